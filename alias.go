@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// aliasPrefix 配置文件中别名的键前缀
+const aliasPrefix = "aliases."
+
+// aliases 返回可用的别名（Program.Aliases 与配置文件中的 aliases.* 合并，后者优先）
+//
+// 与命令（含内置命令）同名的别名会被忽略，别名永远不会遮蔽真正的命令。
+func (p *Program) aliases() (map[string]string, error) {
+	aliases := make(map[string]string)
+	for name, value := range p.Aliases {
+		aliases[name] = value
+	}
+	if p.ConfigFile != "" {
+		config, err := readConfig(p.Path(p.ConfigFile))
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range config {
+			if name, ok := strings.CutPrefix(key, aliasPrefix); ok && name != "" {
+				aliases[name] = value
+			}
+		}
+	}
+	for name := range aliases {
+		if p.get(name) != nil {
+			delete(aliases, name)
+		}
+	}
+	return aliases, nil
+}
+
+// expandAlias 展开别名，返回展开后的命令名称和参数
+//
+// 别名可以引用其它别名，出现循环时返回错误。展开结果为 shell 别名（以 ! 开头）时，
+// script 为要执行的 shell 脚本，name 为空。name 不是别名时原样返回。
+func (p *Program) expandAlias(name string, args []string) (newName string, newArgs []string, script string, err error) {
+	if p.get(name) != nil || (len(p.Aliases) == 0 && p.ConfigFile == "") {
+		return name, args, "", nil
+	}
+	aliases, err := p.aliases()
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	var chain []string
+	for {
+		value, ok := aliases[name]
+		if !ok {
+			return name, args, "", nil
+		}
+		if slices.Contains(chain, name) {
+			return "", nil, "", fmt.Errorf("alias loop: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+		chain = append(chain, name)
+
+		if s, ok := strings.CutPrefix(value, "!"); ok && strings.TrimSpace(s) != "" {
+			return "", args, s, nil
+		}
+		words, err := splitArgs(value)
+		if err != nil {
+			return "", nil, "", fmt.Errorf("alias %s: %w", name, err)
+		}
+		if len(words) == 0 || words[0] == "!" {
+			return "", nil, "", fmt.Errorf("alias %s: empty expansion", name)
+		}
+		name = words[0]
+		args = append(words[1:], args...)
+	}
+}
+
+// runShellAlias 通过系统 shell 执行 shell 别名，参数以 "$@" 追加到脚本之后
+//
+// cmd.exe 无法可靠地转义任意参数，Windows 上不支持 shell 别名。
+func (p *Program) runShellAlias(ctx context.Context, name, script string, args []string) error {
+	if runtime.GOOS == "windows" {
+		return fmt.Errorf("alias %s: shell aliases are not supported on Windows", name)
+	}
+	c := exec.CommandContext(ctx, "sh", append([]string{"-c", script + ` "$@"`, name}, args...)...)
+	return p.runProcess(ctx, c)
+}
+
+// appendAliasUsage 在帮助中追加 ALIASES 部分
+func (p *Program) appendAliasUsage(b []byte, st Theme) []byte {
+	aliases, err := p.aliases()
+	if err != nil || len(aliases) == 0 {
+		return b
+	}
+
+	names := make([]string, 0, len(aliases))
+	maxLen := 0
+	for name := range aliases {
+		names = append(names, name)
+		maxLen = max(maxLen, len(name))
+	}
+	slices.Sort(names)
+
+	b = fmt.Appendf(b, "\n%s\n", st.Heading.Render("ALIASES:"))
+	for _, name := range names {
+		b = fmt.Appendf(b, "    %s    %s\n", st.Command.Render(fmt.Sprintf("%-*s", maxLen, name)), aliases[name])
+	}
+	return b
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newAliasProgram(t *testing.T, config string) (*Program, *[]string, *bytes.Buffer) {
+	t.Helper()
+	prog := NewProgram("testapp", "1.0.0")
+	prog.ConfigFile = filepath.Join(t.TempDir(), "config")
+	writeFile(t, prog.ConfigFile, config)
+	stdout := &bytes.Buffer{}
+	prog.SetStdout(stdout)
+	prog.SetStderr(stdout)
+
+	var got []string
+	var env string
+	deployCmd := NewCommand("deploy", "Deploy the application")
+	deployCmd.Flags.StringVar(&env, "env", "staging", "Environment")
+	deployCmd.Action = func(ctx context.Context, cmd *Command) error {
+		got = append([]string{env}, cmd.Flags.Args()...)
+		return nil
+	}
+	prog.Commands = []*Command{deployCmd}
+	return prog, &got, stdout
+}
+
+func TestProgram_Aliases(t *testing.T) {
+	prog, got, _ := newAliasProgram(t, `[aliases]
+ship = deploy -env production
+release = ship v1
+deploy = deploy -env shadowed
+`)
+
+	if err := prog.Run([]string{"testapp", "ship", "web"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !slices.Equal(*got, []string{"production", "web"}) {
+		t.Errorf("Unexpected expansion: %q", *got)
+	}
+
+	// 别名可以引用其它别名
+	if err := prog.Run([]string{"testapp", "release", "web"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !slices.Equal(*got, []string{"production", "v1", "web"}) {
+		t.Errorf("Unexpected nested expansion: %q", *got)
+	}
+
+	// 别名不会遮蔽真正的命令
+	if err := prog.Run([]string{"testapp", "deploy"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !slices.Equal(*got, []string{"staging"}) {
+		t.Errorf("Expected real command to win over alias, got %q", *got)
+	}
+}
+
+func TestProgram_AliasesFromProgram(t *testing.T) {
+	prog, got, _ := newAliasProgram(t, "aliases.prod = deploy -env from-config\n")
+	prog.Aliases = map[string]string{
+		"prod":    "deploy -env from-program",
+		"version": "deploy",
+		"canary":  "deploy -env canary",
+	}
+
+	if err := prog.Run([]string{"testapp", "prod"}); err != nil || (*got)[0] != "from-config" {
+		t.Errorf("Expected config file to override program alias, got %q (%v)", *got, err)
+	}
+	if err := prog.Run([]string{"testapp", "canary"}); err != nil || (*got)[0] != "canary" {
+		t.Errorf("Expected program alias to be used, got %q (%v)", *got, err)
+	}
+}
+
+func TestProgram_AliasErrors(t *testing.T) {
+	prog, _, _ := newAliasProgram(t, `[aliases]
+a = b
+b = c -x
+c = a
+empty = "  "
+broken = deploy 'oops
+`)
+
+	tests := map[string]string{
+		"a":      "alias loop: a -> b -> c -> a",
+		"empty":  "alias empty: empty expansion",
+		"broken": "alias broken: unterminated quote",
+	}
+	for name, want := range tests {
+		if err := prog.Run([]string{"testapp", name}); err == nil || err.Error() != want {
+			t.Errorf("Run(%q): expected %q, got %v", name, want, err)
+		}
+	}
+}
+
+func TestProgram_ShellAlias(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell aliases use sh")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	prog, _, stdout := newAliasProgram(t, `[aliases]
+hello = "!echo hello"
+fail = !exit 3
+term = !kill -TERM $$
+indirect = fail
+`)
+
+	if err := prog.Run([]string{"testapp", "hello", "a b", "c"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stdout.String() != "hello a b c\n" {
+		t.Errorf("Unexpected output: %q", stdout.String())
+	}
+
+	err := prog.Run([]string{"testapp", "indirect"})
+	if ExitCode(err) != 3 {
+		t.Errorf("Expected exit code 3, got %v", err)
+	}
+
+	err = prog.Run([]string{"testapp", "term"})
+	var sigErr *SignalError
+	if !errors.As(err, &sigErr) || ExitCode(err) != 143 {
+		t.Errorf("Expected SIGTERM to be reported with exit code 143, got %v", err)
+	}
+}
+
+func TestProgram_AliasUsage(t *testing.T) {
+	prog, _, stdout := newAliasProgram(t, `[aliases]
+ship = deploy -env production
+st = "!git status"
+deploy = hidden
+`)
+
+	if err := prog.PrintUsage(); err != nil {
+		t.Fatal(err)
+	}
+	output := stdout.String()
+	if !strings.Contains(output, "ALIASES:\n    ship    deploy -env production\n    st      !git status\n") {
+		t.Errorf("Expected aliases section, got:\n%s", output)
+	}
+	if strings.Contains(output, "hidden") {
+		t.Error("Expected alias shadowed by a command to be hidden")
+	}
+}
+
+func TestProgram_ShellAliasWindows(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("only Windows rejects shell aliases")
+	}
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SetOutput(&bytes.Buffer{})
+	prog.Aliases = map[string]string{"hello": "!echo hello"}
+
+	err := prog.Run([]string{"testapp", "hello", "a&b"})
+	if err == nil || !strings.Contains(err.Error(), "not supported on Windows") {
+		t.Errorf("Expected shell alias to be rejected, got %v", err)
+	}
+}
+
+func TestProgram_ShellAliasCanceled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell aliases use sh")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	prog, _, _ := newAliasProgram(t, "aliases.wait = !exec sleep 5\n")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(50*time.Millisecond, func() {
+		cancel(&SignalError{Signal: syscall.SIGTERM})
+	})
+
+	start := time.Now()
+	err := prog.RunContext(ctx, []string{"testapp", "wait"})
+	if ExitCode(err) != 143 {
+		t.Errorf("Expected the signal to be forwarded to the alias, got %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Error("Expected the alias to stop after cancel")
+	}
+}
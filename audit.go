@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 审计日志文件的默认轮转设置
+const (
+	DefaultAuditMaxSize    = 10 << 20 // 轮转前的最大字节数（10 MiB）
+	DefaultAuditMaxBackups = 3        // 保留的轮转文件数
+)
+
+// AuditRecord 一次命令调用的审计记录
+type AuditRecord struct {
+	Time      time.Time     `json:"time"`                // 开始时间
+	User      string        `json:"user"`                // 执行命令的用户
+	Hostname  string        `json:"hostname"`            // 主机名
+	Dir       string        `json:"dir"`                 // 工作目录
+	Command   string        `json:"command"`             // 命令路径（如 "myapp deploy"）
+	Args      []string      `json:"args"`                // 命令参数（不含程序名和命令名称，敏感标志的值已隐藏）
+	Duration  time.Duration `json:"duration_ns"`         // 执行时间
+	ExitCode  int           `json:"exit_code"`           // 退出码
+	Error     string        `json:"error,omitempty"`     // 错误信息
+	DryRun    bool          `json:"dry_run,omitempty"`   // 是否指定了 --dry-run
+	Confirmed string        `json:"confirmed,omitempty"` // 危险命令的确认方式：prompt（终端确认）或 flag（--yes）
+}
+
+// AuditSink 接收审计记录
+type AuditSink interface {
+	WriteAudit(ctx context.Context, rec *AuditRecord) error
+}
+
+// AuditSinkFunc 将函数适配为 AuditSink
+type AuditSinkFunc func(ctx context.Context, rec *AuditRecord) error
+
+// WriteAudit 实现 AuditSink 接口
+func (f AuditSinkFunc) WriteAudit(ctx context.Context, rec *AuditRecord) error {
+	return f(ctx, rec)
+}
+
+// AuditFile 将审计记录以 JSON Lines 格式追加到文件
+//
+// 写入后文件会超过 MaxSize 时先轮转：<Path>.1 为最新的轮转文件，超过 MaxBackups 的最旧文件被删除。
+// 文件权限为 0600。同一进程内的写入是串行的；多个进程同时轮转时可能丢失一个轮转文件。
+type AuditFile struct {
+	Path       string // 文件路径
+	MaxSize    int64  // 轮转前的最大字节数（默认 DefaultAuditMaxSize）
+	MaxBackups int    // 保留的轮转文件数（默认 DefaultAuditMaxBackups）
+
+	mu sync.Mutex
+}
+
+// WriteAudit 实现 AuditSink 接口
+func (f *AuditFile) WriteAudit(ctx context.Context, rec *AuditRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return err
+	}
+	if info, err := os.Stat(f.Path); err == nil && info.Size() > 0 && info.Size()+int64(len(line)) > f.maxSize() {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(f.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(line)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// maxSize 返回轮转前的最大字节数
+func (f *AuditFile) maxSize() int64 {
+	if f.MaxSize > 0 {
+		return f.MaxSize
+	}
+	return DefaultAuditMaxSize
+}
+
+// maxBackups 返回保留的轮转文件数
+func (f *AuditFile) maxBackups() int {
+	if f.MaxBackups > 0 {
+		return f.MaxBackups
+	}
+	return DefaultAuditMaxBackups
+}
+
+// rotate 将 <Path>.N 依次重命名为 <Path>.N+1，再将当前文件重命名为 <Path>.1（调用方持有锁）
+func (f *AuditFile) rotate() error {
+	n := f.maxBackups()
+	if err := os.Remove(f.backup(n)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for i := n - 1; i >= 1; i-- {
+		if err := os.Rename(f.backup(i), f.backup(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(f.Path, f.backup(1))
+}
+
+// backup 返回第 i 个轮转文件的路径
+func (f *AuditFile) backup(i int) string {
+	return fmt.Sprintf("%s.%d", f.Path, i)
+}
+
+// auditKey context 中存放审计状态的键
+type auditKey struct{}
+
+// auditTrail 在路由和执行过程中收集的审计信息
+//
+// 启用 HandleSignals 时，强制退出前会在信号处理的 goroutine 中写入审计记录，
+// 此时命令仍在另一个 goroutine 中执行，因此需要加锁。
+type auditTrail struct {
+	mu        sync.Mutex
+	routed    bool          // 是否已确定命令
+	command   string        // 命令名称
+	args      []string      // 命令参数（含出现在命令名称之前的全局标志）
+	flags     *flag.FlagSet // 命令的标志集合，用于脱敏
+	dryRun    bool
+	confirmed string
+
+	once  sync.Once
+	write func(err error) // 写入审计记录（由 runAudited 设置）
+}
+
+// auditTrailFrom 返回 context 中的审计状态，未设置 Audit 时返回 nil
+func auditTrailFrom(ctx context.Context) *auditTrail {
+	t, _ := ctx.Value(auditKey{}).(*auditTrail)
+	return t
+}
+
+// setCommand 记录路由到的命令名称和参数
+func (t *auditTrail) setCommand(name string, args []string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routed = true
+	t.command = name
+	t.args = slices.Clone(args)
+}
+
+// setInvocation 记录本次调用的标志集合、dry-run 和确认状态
+func (t *auditTrail) setInvocation(inv *Command) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.flags = inv.Flags
+	t.dryRun = inv.IsDryRun()
+	t.confirmed = inv.confirmedBy
+}
+
+// finish 以 err 作为结果写入审计记录，只有第一次调用生效
+func (t *auditTrail) finish(err error) {
+	if t == nil {
+		return
+	}
+	t.once.Do(func() { t.write(err) })
+}
+
+// finishPanic 发生 panic 时写入审计记录，然后继续 panic
+//
+// 必须通过 defer 直接调用。
+func (t *auditTrail) finishPanic() {
+	if t == nil {
+		return
+	}
+	if r := recover(); r != nil {
+		t.mu.Lock()
+		command := t.command
+		t.mu.Unlock()
+		t.finish(&PanicError{Command: command, Value: r})
+		panic(r)
+	}
+}
+
+// runAudited 执行 run，设置了 Audit 时在结束后写入审计记录
+//
+// 未启用 RecoverPanics 时 panic 也会被记录（之后继续 panic）；启用 HandleSignals 时，
+// 强制退出前会以 *SignalError 写入记录。写入失败时在标准错误输出警告，不影响命令的返回值。
+func (p *Program) runAudited(ctx context.Context, args []string, run func(ctx context.Context, args []string) error) error {
+	if p.Audit == nil {
+		return run(ctx, args)
+	}
+
+	start := p.Now()
+	trail := &auditTrail{}
+	trail.write = func(err error) {
+		rec := p.auditRecord(trail, args, start, err)
+		if werr := p.Audit.WriteAudit(context.WithoutCancel(ctx), rec); werr != nil {
+			p.printWarning("Failed to write audit log: %v", werr)
+		}
+	}
+	defer trail.finishPanic()
+
+	err := run(context.WithValue(ctx, auditKey{}, trail), args)
+	trail.finish(err)
+	return err
+}
+
+// auditRecord 创建审计记录
+func (p *Program) auditRecord(trail *auditTrail, args []string, start time.Time, err error) *AuditRecord {
+	trail.mu.Lock()
+	defer trail.mu.Unlock()
+
+	rec := &AuditRecord{
+		Time:      start,
+		User:      p.Username(),
+		Hostname:  p.Hostname(),
+		Dir:       p.Dir(),
+		Command:   strings.TrimSpace(p.Name + " " + trail.command),
+		Args:      []string{},
+		Duration:  p.Now().Sub(start),
+		ExitCode:  ExitCode(err),
+		DryRun:    trail.dryRun,
+		Confirmed: trail.confirmed,
+	}
+	switch {
+	case trail.routed:
+		rec.Args = p.redactArgs(trail.flags, trail.args)
+	case len(args) > 1:
+		// 未路由到命令（如 --help），记录程序名之后的全部参数
+		rec.Args = p.redactArgs(nil, args[1:])
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	return rec
+}
@@ -0,0 +1,284 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newAuditProgram 创建将审计记录收集到切片中的程序
+func newAuditProgram() (*Program, *[]*AuditRecord, *bytes.Buffer, *testClock) {
+	prog := NewProgram("testapp", "1.0.0")
+	records := &[]*AuditRecord{}
+	prog.Audit = AuditSinkFunc(func(ctx context.Context, rec *AuditRecord) error {
+		*records = append(*records, rec)
+		return nil
+	})
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	prog.SetClock(clock.Now)
+	prog.SetDir("/srv/app")
+	prog.SetHostname("build-01")
+	prog.SetUsername("ada")
+	stderr := &bytes.Buffer{}
+	prog.SetStdout(&bytes.Buffer{})
+	prog.SetStderr(stderr)
+
+	deployCmd := NewCommand("deploy", "Deploy the application")
+	deployCmd.Flags.String("token", "", "API token")
+	deployCmd.Flags.String("env", "", "Environment")
+	deployCmd.Action = func(ctx context.Context, cmd *Command) error {
+		clock.Advance(2 * time.Second)
+		if cmd.Flags.Lookup("env").Value.String() == "bad" {
+			return errors.New("deploy failed")
+		}
+		return nil
+	}
+
+	purgeCmd := NewCommand("purge", "Purge all caches")
+	purgeCmd.Dangerous = true
+	purgeCmd.Action = func(ctx context.Context, cmd *Command) error { return nil }
+
+	prog.Commands = []*Command{deployCmd, purgeCmd}
+	return prog, records, stderr, clock
+}
+
+func TestProgram_Audit(t *testing.T) {
+	prog, records, _, _ := newAuditProgram()
+
+	if err := prog.Run([]string{"testapp", "deploy", "-token", "s3cret", "--env=prod"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*records) != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", len(*records))
+	}
+	rec := (*records)[0]
+	if !rec.Time.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || rec.Duration != 2*time.Second {
+		t.Errorf("Unexpected time %v and duration %v", rec.Time, rec.Duration)
+	}
+	if rec.User != "ada" || rec.Hostname != "build-01" || rec.Dir != "/srv/app" {
+		t.Errorf("Unexpected user %q, hostname %q or dir %q", rec.User, rec.Hostname, rec.Dir)
+	}
+	if rec.Command != "testapp deploy" || strings.Join(rec.Args, " ") != "-token *** --env=prod" {
+		t.Errorf("Unexpected command %q and args %q", rec.Command, rec.Args)
+	}
+	if rec.ExitCode != 0 || rec.Error != "" || rec.DryRun || rec.Confirmed != "" {
+		t.Errorf("Unexpected record: %+v", rec)
+	}
+}
+
+func TestProgram_AuditError(t *testing.T) {
+	prog, records, _, _ := newAuditProgram()
+
+	if err := prog.Run([]string{"testapp", "deploy", "-env", "bad"}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if err := prog.Run([]string{"testapp", "missing"}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if len(*records) != 2 {
+		t.Fatalf("Expected 2 audit records, got %d", len(*records))
+	}
+	if rec := (*records)[0]; rec.ExitCode != 1 || rec.Error != "deploy failed" {
+		t.Errorf("Unexpected record: %+v", rec)
+	}
+	if rec := (*records)[1]; rec.Command != "testapp missing" || rec.Error != "unknown command: missing" {
+		t.Errorf("Unexpected record: %+v", rec)
+	}
+}
+
+func TestProgram_AuditLeadingGlobalFlags(t *testing.T) {
+	prog, records, _, _ := newAuditProgram()
+	prog.EnableDryRun = true
+
+	if err := prog.Run([]string{"testapp", "--dry-run", "deploy", "-token", "s3cret"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := prog.Run([]string{"testapp", "--help"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec := (*records)[0]; rec.Command != "testapp deploy" || strings.Join(rec.Args, " ") != "--dry-run -token ***" || !rec.DryRun {
+		t.Errorf("Unexpected command %q and args %q", rec.Command, rec.Args)
+	}
+	// 未路由到命令时记录全部参数
+	if rec := (*records)[1]; rec.Command != "testapp" || strings.Join(rec.Args, " ") != "--help" {
+		t.Errorf("Unexpected command %q and args %q", rec.Command, rec.Args)
+	}
+}
+
+func TestProgram_AuditConfirmed(t *testing.T) {
+	prog, records, _, _ := newAuditProgram()
+	prog.EnableDryRun = true
+
+	if err := prog.Run([]string{"testapp", "purge", "-y"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	prog.SetStdin(ttyReader{strings.NewReader("y\n")})
+	if err := prog.Run([]string{"testapp", "purge"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := prog.Run([]string{"testapp", "--dry-run", "purge"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, rec := range *records {
+		got = append(got, rec.Confirmed+"/"+map[bool]string{true: "dry", false: "real"}[rec.DryRun])
+	}
+	if strings.Join(got, ",") != "flag/real,prompt/real,/dry" {
+		t.Errorf("Unexpected confirmations: %v", got)
+	}
+}
+
+func TestProgram_AuditScript(t *testing.T) {
+	prog, records, _, _ := newAuditProgram()
+	prog.EnableScript = true
+
+	script := "deploy -env prod\ndeploy -env staging\n"
+	if _, err := prog.RunScript(context.Background(), strings.NewReader(script)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*records) != 2 {
+		t.Fatalf("Expected a record per line, got %d", len(*records))
+	}
+	if got := strings.Join((*records)[1].Args, " "); got != "-env staging" {
+		t.Errorf("Unexpected args: %q", got)
+	}
+}
+
+func TestProgram_AuditPanic(t *testing.T) {
+	prog, records, _, _ := newAuditProgram()
+	crashCmd := NewCommand("crash", "Crash")
+	crashCmd.Action = func(ctx context.Context, cmd *Command) error { panic("boom") }
+	prog.Commands = append(prog.Commands, crashCmd)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Expected the panic to propagate, got %v", r)
+			}
+		}()
+		_ = prog.Run([]string{"testapp", "crash"})
+	}()
+
+	if len(*records) != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", len(*records))
+	}
+	if rec := (*records)[0]; rec.Command != "testapp crash" || rec.ExitCode != 2 || rec.Error != `panic in command "crash": boom` {
+		t.Errorf("Unexpected record: %+v", rec)
+	}
+}
+
+func TestProgram_AuditForcedExit(t *testing.T) {
+	prog, records, _, _ := newAuditProgram()
+	prog.HandleSignals = true
+	prog.ShutdownTimeout = 10 * time.Millisecond
+	registered := fakeSignals(prog)
+
+	// 记录必须在退出前写入
+	written := -1
+	prog.SetExitFunc(func(code int) { written = len(*records) })
+
+	release := make(chan struct{})
+	defer close(release)
+	prog.Commands[0].Action = func(ctx context.Context, cmd *Command) error {
+		(<-registered) <- syscall.SIGTERM
+		<-release // 忽略取消，模拟卡住的 Action
+		return nil
+	}
+
+	// -token 的值以 "-" 开头，只有按标志定义才能识别
+	_ = prog.Run([]string{"testapp", "deploy", "-token", "-s3cret"})
+	if written != 1 || len(*records) != 1 {
+		t.Fatalf("Expected a single record written before exit, got %d before and %d after", written, len(*records))
+	}
+	rec := (*records)[0]
+	if rec.ExitCode != 143 || rec.Error != "interrupted by signal: terminated" {
+		t.Errorf("Unexpected record: %+v", rec)
+	}
+	if rec.Command != "testapp deploy" || strings.Join(rec.Args, " ") != "-token ***" {
+		t.Errorf("Unexpected command %q and args %q", rec.Command, rec.Args)
+	}
+}
+
+func TestProgram_AuditSinkError(t *testing.T) {
+	prog, _, stderr, _ := newAuditProgram()
+	prog.Audit = AuditSinkFunc(func(ctx context.Context, rec *AuditRecord) error {
+		return errors.New("disk full")
+	})
+
+	if err := prog.Run([]string{"testapp", "deploy"}); err != nil {
+		t.Fatalf("Expected sink errors not to fail the command, got: %v", err)
+	}
+	if stderr.String() != "Failed to write audit log: disk full\n" {
+		t.Errorf("Unexpected warning: %q", stderr.String())
+	}
+}
+
+func TestAuditFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	f := &AuditFile{Path: path}
+	rec := &AuditRecord{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Command: "testapp deploy", Args: []string{"deploy"}, Duration: time.Second}
+
+	for range 2 {
+		if err := f.WriteAudit(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"time":"2024-01-01T00:00:00Z","user":"","hostname":"","dir":"","command":"testapp deploy","args":["deploy"],"duration_ns":1000000000,"exit_code":0}` + "\n"
+	if string(data) != want+want {
+		t.Errorf("Unexpected audit file:\n%s", data)
+	}
+	if info, err := os.Stat(path); err != nil || (runtime.GOOS != "windows" && info.Mode().Perm() != 0o600) {
+		t.Errorf("Expected mode 0600, got %v (%v)", info.Mode(), err)
+	}
+}
+
+func TestAuditFile_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	// 每个文件只能容纳一条记录
+	f := &AuditFile{Path: path, MaxSize: 100, MaxBackups: 2}
+
+	for i := range 5 {
+		rec := &AuditRecord{Command: "testapp", ExitCode: i}
+		if err := f.WriteAudit(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, want := range map[string]int{path: 4, path + ".1": 3, path + ".2": 2} {
+		file, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lines []AuditRecord
+		sc := bufio.NewScanner(file)
+		for sc.Scan() {
+			var rec AuditRecord
+			if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+				t.Fatal(err)
+			}
+			lines = append(lines, rec)
+		}
+		file.Close()
+		if len(lines) != 1 || lines[0].ExitCode != want {
+			t.Errorf("%s: expected record %d, got %+v", filepath.Base(name), want, lines)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected at most 2 backups, got %v", err)
+	}
+}
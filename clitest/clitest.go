@@ -0,0 +1,249 @@
+// Package clitest 提供在进程内执行 cli.Program 的测试工具
+//
+// Run 捕获标准输出、标准错误、退出码和错误，并为程序注入独立的环境变量、
+// 标准输入、工作目录、时钟、主机名和用户名，测试不会读取或修改真实的进程状态：
+//
+//	func TestGreet(t *testing.T) {
+//		res := clitest.Run(t, newApp(), "greet", "-name", "ada")
+//		res.AssertSuccess().AssertStdout("hello ada\n")
+//	}
+//
+// 需要定制环境时使用 New 创建 Harness：
+//
+//	h := clitest.New(t, newApp())
+//	h.Env["API_TOKEN"] = "secret"
+//	h.Stdin = "yes\n"
+//	h.Run("deploy").AssertExitCode(0)
+package clitest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hupeh/cli"
+)
+
+// Harness 执行程序的测试环境
+//
+// 每次 Run 都在 Program 的副本上执行，副本的输入输出、环境变量、工作目录、时钟、主机名、用户名
+// 和崩溃报告目录替换为 Harness 中的设置；副本不接收真实的信号，强制退出时也不会结束测试进程。
+// Program 本身不会被修改，因此同一个 Program 可以在多个 Harness 中执行。
+type Harness struct {
+	t       testing.TB
+	Program *cli.Program
+
+	Env      map[string]string // 环境变量（默认为空，不继承进程的环境变量）
+	Stdin    io.Reader         // 标准输入（默认为空输入，字符串可以使用 SetStdin）
+	TTY      bool              // 模拟终端：标准输入输出实现 cli.Terminal（用于测试交互式询问）
+	Dir      string            // 工作目录（默认为 t.TempDir()）
+	Clock    *Clock            // 时钟（默认固定在 DefaultTime）
+	Hostname string            // 主机名（默认为 DefaultHostname）
+	Username string            // 用户名（默认为 DefaultUsername）
+	Context  context.Context   // 执行使用的 context（默认 t.Context()）
+
+	CrashReportDir string // 崩溃报告目录（默认为 t.TempDir()）
+}
+
+// 测试环境中默认的主机名和用户名
+const (
+	DefaultHostname = "localhost"
+	DefaultUsername = "tester"
+)
+
+// New 创建测试环境
+func New(t testing.TB, p *cli.Program) *Harness {
+	t.Helper()
+	return &Harness{
+		t:        t,
+		Program:  p,
+		Env:      map[string]string{},
+		Dir:      t.TempDir(),
+		Clock:    NewClock(DefaultTime),
+		Hostname: DefaultHostname,
+		Username: DefaultUsername,
+		Context:  t.Context(),
+
+		CrashReportDir: t.TempDir(),
+	}
+}
+
+// SetStdin 使用字符串作为标准输入
+func (h *Harness) SetStdin(s string) *Harness {
+	h.Stdin = strings.NewReader(s)
+	return h
+}
+
+// Run 执行程序，args 不包含程序名称
+func (h *Harness) Run(args ...string) *Result {
+	h.t.Helper()
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	p := h.program(stdout, stderr)
+
+	ctx := h.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := p.RunContext(ctx, append([]string{p.Name}, args...))
+
+	return &Result{
+		t:        h.t,
+		Args:     args,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: cli.ExitCode(err),
+		Err:      err,
+	}
+}
+
+// program 返回使用 Harness 设置的程序副本
+func (h *Harness) program(stdout, stderr io.Writer) *cli.Program {
+	prog := *h.Program
+	p := &prog
+	stdin := h.Stdin
+	if stdin == nil {
+		stdin = strings.NewReader("")
+	}
+
+	env := make([]string, 0, len(h.Env))
+	for k, v := range h.Env {
+		env = append(env, k+"="+v)
+	}
+	slices.Sort(env)
+
+	if h.TTY {
+		p.SetStdin(terminalReader{stdin})
+		p.SetStdout(terminalWriter{stdout})
+		p.SetStderr(terminalWriter{stderr})
+	} else {
+		p.SetStdin(stdin)
+		p.SetStdout(stdout)
+		p.SetStderr(stderr)
+	}
+	p.SetEnviron(env)
+	p.SetDir(h.Dir)
+	p.SetClock(h.Clock.Now)
+	p.SetHostname(h.Hostname)
+	p.SetUsername(h.Username)
+	p.SetSignalNotify(func(chan<- os.Signal, ...os.Signal) {})
+	p.SetExitFunc(func(int) {})
+	p.CrashReportDir = h.CrashReportDir
+	return p
+}
+
+// terminalReader 模拟终端的输入
+type terminalReader struct {
+	io.Reader
+}
+
+// IsTerminal 实现 cli.Terminal 接口
+func (terminalReader) IsTerminal() bool { return true }
+
+// terminalWriter 模拟终端的输出
+type terminalWriter struct {
+	io.Writer
+}
+
+// IsTerminal 实现 cli.Terminal 接口
+func (terminalWriter) IsTerminal() bool { return true }
+
+// Run 在默认的测试环境中执行程序，args 不包含程序名称
+func Run(t testing.TB, p *cli.Program, args ...string) *Result {
+	t.Helper()
+	return New(t, p).Run(args...)
+}
+
+// Result 一次执行的结果
+//
+// 断言方法失败时调用 t.Errorf 并返回 Result 本身，可以链式调用。
+type Result struct {
+	t        testing.TB
+	Args     []string // 执行的参数
+	Stdout   string   // 标准输出
+	Stderr   string   // 标准错误
+	ExitCode int      // 退出码（见 cli.ExitCode）
+	Err      error    // RunContext 返回的错误
+}
+
+// AssertSuccess 断言执行成功
+func (r *Result) AssertSuccess() *Result {
+	r.t.Helper()
+	if r.Err != nil {
+		r.errorf("expected success, got error: %v", r.Err)
+	}
+	return r
+}
+
+// AssertExitCode 断言退出码
+func (r *Result) AssertExitCode(code int) *Result {
+	r.t.Helper()
+	if r.ExitCode != code {
+		r.errorf("expected exit code %d, got %d (error: %v)", code, r.ExitCode, r.Err)
+	}
+	return r
+}
+
+// AssertError 断言执行失败且错误信息包含 substr
+func (r *Result) AssertError(substr string) *Result {
+	r.t.Helper()
+	switch {
+	case r.Err == nil:
+		r.errorf("expected error containing %q, got success", substr)
+	case !strings.Contains(r.Err.Error(), substr):
+		r.errorf("expected error containing %q, got %q", substr, r.Err.Error())
+	}
+	return r
+}
+
+// AssertStdout 断言标准输出与 want 完全相同
+func (r *Result) AssertStdout(want string) *Result {
+	r.t.Helper()
+	if r.Stdout != want {
+		r.errorf("stdout mismatch\n--- want ---\n%s\n--- got ---\n%s", want, r.Stdout)
+	}
+	return r
+}
+
+// AssertStdoutContains 断言标准输出包含所有 substrs
+func (r *Result) AssertStdoutContains(substrs ...string) *Result {
+	r.t.Helper()
+	for _, s := range substrs {
+		if !strings.Contains(r.Stdout, s) {
+			r.errorf("expected stdout to contain %q, got:\n%s", s, r.Stdout)
+		}
+	}
+	return r
+}
+
+// AssertStderr 断言标准错误与 want 完全相同
+func (r *Result) AssertStderr(want string) *Result {
+	r.t.Helper()
+	if r.Stderr != want {
+		r.errorf("stderr mismatch\n--- want ---\n%s\n--- got ---\n%s", want, r.Stderr)
+	}
+	return r
+}
+
+// AssertStderrContains 断言标准错误包含所有 substrs
+func (r *Result) AssertStderrContains(substrs ...string) *Result {
+	r.t.Helper()
+	for _, s := range substrs {
+		if !strings.Contains(r.Stderr, s) {
+			r.errorf("expected stderr to contain %q, got:\n%s", s, r.Stderr)
+		}
+	}
+	return r
+}
+
+// errorf 报告断言失败，信息中包含执行的参数
+func (r *Result) errorf(format string, args ...any) {
+	r.t.Helper()
+	r.t.Errorf("%s: %s", strings.Join(r.Args, " "), fmt.Sprintf(format, args...))
+}
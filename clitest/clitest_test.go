@@ -0,0 +1,209 @@
+package clitest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hupeh/cli"
+)
+
+// exitError 带退出码的测试错误
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string { return fmt.Sprintf("exit status %d", e.code) }
+func (e *exitError) ExitCode() int { return e.code }
+
+func newApp() *cli.Program {
+	app := cli.NewProgram("testapp", "1.0.0")
+
+	var name string
+	greetCmd := cli.NewCommand("greet", "Greet someone")
+	greetCmd.Flags.StringVar(&name, "name", "world", "Name")
+	greetCmd.Action = func(ctx context.Context, cmd *cli.Command) error {
+		fmt.Fprintf(cmd.Stdout(), "hello %s\n", name)
+		return nil
+	}
+
+	envCmd := cli.NewCommand("env", "Print environment")
+	envCmd.Action = func(ctx context.Context, cmd *cli.Command) error {
+		fmt.Fprintf(cmd.Stdout(), "token=%s home=%q\n", cmd.Getenv("API_TOKEN"), cmd.Getenv("HOME"))
+		fmt.Fprintf(cmd.Stdout(), "dir=%s\n", cmd.Dir())
+		fmt.Fprintf(cmd.Stdout(), "now=%s\n", cmd.Now().Format(time.RFC3339))
+		fmt.Fprintf(cmd.Stdout(), "host=%s user=%s\n", cmd.Hostname(), cmd.Username())
+		return nil
+	}
+
+	writeCmd := cli.NewCommand("write", "Write a file relative to the working directory")
+	writeCmd.Action = func(ctx context.Context, cmd *cli.Command) error {
+		return os.WriteFile(cmd.Path(cmd.Flags.Arg(0)), []byte("data"), 0o600)
+	}
+
+	failCmd := cli.NewCommand("fail", "Fail")
+	failCmd.Action = func(ctx context.Context, cmd *cli.Command) error {
+		fmt.Fprintln(cmd.Stderr(), "something broke")
+		return &exitError{code: 3}
+	}
+
+	var env string
+	askCmd := cli.NewCommand("ask", "Ask for a value")
+	askCmd.Interactive = true
+	askCmd.Flags.StringVar(&env, "env", "", "Environment")
+	askCmd.Params = []cli.Param{{Name: "env", Required: true}}
+	askCmd.Action = func(ctx context.Context, cmd *cli.Command) error {
+		fmt.Fprintf(cmd.Stdout(), "env=%s\n", env)
+		return nil
+	}
+
+	app.Commands = []*cli.Command{greetCmd, envCmd, writeCmd, failCmd, askCmd}
+	return app
+}
+
+func TestRun(t *testing.T) {
+	Run(t, newApp(), "greet", "-name", "ada").
+		AssertSuccess().
+		AssertExitCode(0).
+		AssertStdout("hello ada\n").
+		AssertStderr("")
+
+	Run(t, newApp(), "fail").
+		AssertExitCode(3).
+		AssertError("exit status 3").
+		AssertStderrContains("something broke")
+
+	Run(t, newApp(), "nope").
+		AssertExitCode(1).
+		AssertError("unknown command: nope").
+		AssertStderrContains("Unknown command: nope", "COMMANDS:")
+}
+
+func TestHarness_Isolation(t *testing.T) {
+	t.Setenv("API_TOKEN", "from-process")
+	t.Setenv("HOME", "/real/home")
+
+	h := New(t, newApp())
+	h.Env["API_TOKEN"] = "injected"
+	h.Clock.Advance(90 * time.Minute)
+	h.Username = "ada"
+
+	h.Run("env").AssertSuccess().AssertStdout(fmt.Sprintf(
+		"token=injected home=\"\"\ndir=%s\nnow=2024-01-01T01:30:00Z\nhost=localhost user=ada\n", h.Dir))
+
+	// 相对路径写入 Harness 的工作目录
+	h.Run("write", "out.txt").AssertSuccess()
+	if _, err := os.Stat(filepath.Join(h.Dir, "out.txt")); err != nil {
+		t.Errorf("Expected file in harness dir: %v", err)
+	}
+}
+
+func TestHarness_Stdin(t *testing.T) {
+	h := New(t, newApp())
+	h.SetStdin("production\n")
+
+	// 非终端时不询问
+	h.Run("ask").AssertExitCode(1).AssertError("missing required flag: -env")
+
+	h.SetStdin("production\n")
+	h.TTY = true
+	h.Run("ask").AssertSuccess().AssertStdout("env=production\n").AssertStderrContains("Environment: ")
+}
+
+func TestHarness_Context(t *testing.T) {
+	app := newApp()
+	waitCmd := cli.NewCommand("wait", "Wait")
+	waitCmd.Action = func(ctx context.Context, cmd *cli.Command) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	app.Commands = append(app.Commands, waitCmd)
+
+	h := New(t, app)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h.Context = ctx
+
+	res := h.Run("wait")
+	if !errors.Is(res.Err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", res.Err)
+	}
+}
+
+func TestHarness_ProgramCopy(t *testing.T) {
+	app := newApp()
+	app.RecoverPanics = true
+	panicCmd := cli.NewCommand("panic", "Panic")
+	panicCmd.Action = func(ctx context.Context, cmd *cli.Command) error {
+		panic("boom")
+	}
+	app.Commands = append(app.Commands, panicCmd)
+
+	h := New(t, app)
+	h.Run("panic").AssertExitCode(2).AssertStderrContains("crash report has been written to " + h.CrashReportDir)
+	reports, _ := filepath.Glob(filepath.Join(h.CrashReportDir, "testapp-crash-*.log"))
+	if len(reports) != 1 {
+		t.Errorf("Expected one crash report in the harness directory, got %v", reports)
+	}
+
+	// 原始程序没有被修改
+	if app.Stdout() != os.Stdout || app.Dir() == h.Dir || app.CrashReportDir != "" {
+		t.Error("Expected harness to leave the original program untouched")
+	}
+}
+
+// recorder 记录断言失败而不让测试失败
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestResult_AssertionFailures(t *testing.T) {
+	rec := &recorder{TB: t}
+	res := &Result{t: rec, Args: []string{"greet"}, Stdout: "hello\n", ExitCode: 2, Err: errors.New("boom")}
+
+	res.AssertSuccess().
+		AssertExitCode(0).
+		AssertError("other").
+		AssertStdout("bye\n").
+		AssertStdoutContains("hello", "missing").
+		AssertStderr("x").
+		AssertStderrContains("y")
+
+	if len(rec.failures) != 7 {
+		t.Fatalf("Expected 7 failures, got %d: %q", len(rec.failures), rec.failures)
+	}
+	if !strings.HasPrefix(rec.failures[0], "greet: expected success") {
+		t.Errorf("Expected failure to mention the args, got %q", rec.failures[0])
+	}
+
+	rec.failures = nil
+	(&Result{t: rec}).AssertError("anything")
+	if len(rec.failures) != 1 {
+		t.Error("Expected AssertError to fail on success")
+	}
+}
+
+func TestClock(t *testing.T) {
+	c := NewClock(DefaultTime)
+	c.Advance(time.Hour)
+	if !c.Now().Equal(DefaultTime.Add(time.Hour)) {
+		t.Errorf("Unexpected time after Advance: %v", c.Now())
+	}
+	later := DefaultTime.AddDate(1, 0, 0)
+	c.Set(later)
+	if !c.Now().Equal(later) {
+		t.Errorf("Unexpected time after Set: %v", c.Now())
+	}
+}
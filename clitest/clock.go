@@ -0,0 +1,41 @@
+package clitest
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTime 测试时钟的默认时间
+var DefaultTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock 可以手动拨动的测试时钟
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock 创建停在 t 的时钟
+func NewClock(t time.Time) *Clock {
+	return &Clock{now: t}
+}
+
+// Now 返回当前时间
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set 将时钟设置为 t
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Advance 将时钟向前拨动 d
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
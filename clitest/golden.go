@@ -0,0 +1,165 @@
+package clitest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hupeh/cli"
+)
+
+// Update 为 true 时重写黄金文件而不是比较（go test -clitest.update）
+//
+// 以下情况同样会重写黄金文件：环境变量 CLITEST_UPDATE 为真值（适用于 go test ./...），
+// 或测试包自己定义的 -update 布尔标志为 true。
+//
+// clitest 不直接注册 -update：被导入的包先于测试包初始化，测试包再定义同名标志（常见的黄金文件惯例）
+// 时会因 flag redefined 而 panic。需要 go test -update 时在测试包中定义该标志即可：
+//
+//	var _ = flag.Bool("update", false, "rewrite golden files")
+var Update = flag.Bool("clitest.update", false, "rewrite golden files instead of comparing")
+
+// updating 判断是否应重写黄金文件
+func updating() bool {
+	if *Update {
+		return true
+	}
+	if v, err := strconv.ParseBool(os.Getenv("CLITEST_UPDATE")); err == nil && v {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		if g, ok := f.Value.(flag.Getter); ok {
+			v, _ := g.Get().(bool)
+			return v
+		}
+	}
+	return false
+}
+
+// HelpGoldenDir 帮助快照所在的目录（相对于测试所在的包目录）
+var HelpGoldenDir = filepath.Join("testdata", "help")
+
+// AssertHelpGolden 将程序和每个命令的帮助与 HelpGoldenDir 下的黄金文件比较
+//
+// Program.PrintUsage 的输出保存在 <Name>.golden，每个命令的 Command.PrintUsage 输出
+// 保存在 <Name>-<命令>.golden（包括已启用的内置命令）。帮助在 New 创建的隔离环境中渲染，不受真实环境变量影响。
+// 已删除命令的黄金文件会被报告为错误；设置了 Update 时重写所有黄金文件并删除多余的文件。
+func AssertHelpGolden(t testing.TB, p *cli.Program) {
+	t.Helper()
+	h := New(t, p)
+
+	snapshots := map[string]string{
+		p.Name + ".golden": h.render(func(prog *cli.Program) error { return prog.PrintUsage() }),
+	}
+	for _, name := range commandNames(p) {
+		snapshots[p.Name+"-"+name+".golden"] = h.render(func(prog *cli.Program) error {
+			return prog.Get(name).PrintUsage()
+		})
+	}
+
+	names := make([]string, 0, len(snapshots))
+	for name := range snapshots {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		AssertGolden(t, filepath.Join(HelpGoldenDir, name), snapshots[name])
+	}
+
+	// 检查已删除命令遗留的黄金文件
+	stale, _ := filepath.Glob(filepath.Join(HelpGoldenDir, p.Name+"-*.golden"))
+	for _, path := range stale {
+		if _, ok := snapshots[filepath.Base(path)]; ok {
+			continue
+		}
+		if updating() {
+			if err := os.Remove(path); err != nil {
+				t.Errorf("remove stale golden file: %v", err)
+			}
+			continue
+		}
+		t.Errorf("%s: golden file for a command that no longer exists (run with -clitest.update to remove)", path)
+	}
+}
+
+// commandNames 返回注册的命令和已启用的内置命令的名称
+func commandNames(p *cli.Program) []string {
+	var names []string
+	for _, cmd := range p.Commands {
+		names = append(names, cmd.Name)
+	}
+	for _, name := range []string{"help", "version", "shell", "run-script", "update"} {
+		if !slices.Contains(names, name) && p.Get(name) != nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// render 在隔离环境中使用程序的副本执行 fn 并返回其标准输出
+func (h *Harness) render(fn func(p *cli.Program) error) string {
+	h.t.Helper()
+	var stdout, stderr bytes.Buffer
+	if err := fn(h.program(&stdout, &stderr)); err != nil {
+		h.t.Errorf("render help: %v", err)
+	}
+	return stdout.String()
+}
+
+// AssertGolden 将 got 与黄金文件 path 的内容比较，设置了 Update 时写入 got
+func AssertGolden(t testing.TB, path, got string) {
+	t.Helper()
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create golden dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("%s: golden file does not exist (run with -clitest.update to create it)", path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if string(want) != got {
+		t.Errorf("%s: output differs from golden file (run with -clitest.update to accept)\n%s", path, diffLines(string(want), got))
+	}
+}
+
+// diffLines 返回两段文本第一处不同的行及其上下文
+func diffLines(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	i := 0
+	for i < len(wantLines) && i < len(gotLines) && wantLines[i] == gotLines[i] {
+		i++
+	}
+	line := func(lines []string, i int) string {
+		if i < len(lines) {
+			return lines[i]
+		}
+		return "<EOF>"
+	}
+
+	var b strings.Builder
+	for j := max(i-2, 0); j < i; j++ {
+		b.WriteString("    " + wantLines[j] + "\n")
+	}
+	b.WriteString("  - " + line(wantLines, i) + "\n")
+	b.WriteString("  + " + line(gotLines, i))
+	return "line " + strconv.Itoa(i+1) + ":\n" + b.String()
+}
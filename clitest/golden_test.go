@@ -0,0 +1,125 @@
+package clitest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 测试包可以定义自己的 -update 标志，不会与 clitest 冲突
+var updateFlag = flag.Bool("update", false, "rewrite golden files")
+
+func TestAssertHelpGolden(t *testing.T) {
+	AssertHelpGolden(t, newApp())
+}
+
+// withGoldenDir 在临时目录中执行黄金文件测试
+func withGoldenDir(t *testing.T, update bool) string {
+	dir := t.TempDir()
+	oldDir, oldUpdate, oldFlag := HelpGoldenDir, *Update, *updateFlag
+	HelpGoldenDir, *Update, *updateFlag = dir, update, false
+	t.Setenv("CLITEST_UPDATE", "")
+	t.Cleanup(func() {
+		HelpGoldenDir, *Update, *updateFlag = oldDir, oldUpdate, oldFlag
+	})
+	return dir
+}
+
+func TestAssertHelpGolden_Update(t *testing.T) {
+	dir := withGoldenDir(t, true)
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile("testapp-removed.golden", "old")
+
+	AssertHelpGolden(t, newApp())
+
+	for _, name := range []string{"testapp.golden", "testapp-greet.golden", "testapp-help.golden", "testapp-version.golden"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "testapp-removed.golden")); !os.IsNotExist(err) {
+		t.Error("Expected stale golden file to be removed")
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "testapp-greet.golden"))
+	if !strings.Contains(string(data), "Usage: testapp greet [options]") {
+		t.Errorf("Expected command usage in golden file, got %q", data)
+	}
+}
+
+func TestAssertHelpGolden_Mismatch(t *testing.T) {
+	dir := withGoldenDir(t, true)
+	AssertHelpGolden(t, newApp())
+	*Update = false
+
+	app := newApp()
+	app.Commands[0].Usage = "Say hello"
+	if err := os.WriteFile(filepath.Join(dir, "testapp-removed.golden"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &recorder{TB: t}
+	AssertHelpGolden(rec, app)
+
+	joined := strings.Join(rec.failures, "\n")
+	for _, want := range []string{
+		"testapp.golden: output differs",
+		"testapp-greet.golden: output differs",
+		"- Greet someone",
+		"+ Say hello",
+		"testapp-removed.golden: golden file for a command that no longer exists",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected failure containing %q, got:\n%s", want, joined)
+		}
+	}
+	if len(rec.failures) != 3 {
+		t.Errorf("Expected 3 failures, got %d:\n%s", len(rec.failures), joined)
+	}
+}
+
+func TestAssertGolden_Missing(t *testing.T) {
+	dir := withGoldenDir(t, false)
+	rec := &recorder{TB: t}
+	AssertGolden(rec, filepath.Join(dir, "missing.golden"), "x")
+	if len(rec.failures) != 1 || !strings.Contains(rec.failures[0], "run with -clitest.update to create it") {
+		t.Errorf("Expected missing golden file failure, got %q", rec.failures)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines("a\nb\nc\nd\n", "a\nb\nc\nx\n")
+	want := "line 4:\n    b\n    c\n  - d\n  + x"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := diffLines("a", "a\nb"); !strings.Contains(got, "- <EOF>") {
+		t.Errorf("Expected <EOF> marker, got %q", got)
+	}
+}
+
+func TestUpdating(t *testing.T) {
+	withGoldenDir(t, false)
+	if updating() {
+		t.Fatal("Expected updating to be false by default")
+	}
+
+	t.Setenv("CLITEST_UPDATE", "1")
+	if !updating() {
+		t.Error("Expected CLITEST_UPDATE=1 to enable updating")
+	}
+	t.Setenv("CLITEST_UPDATE", "")
+
+	*updateFlag = true
+	if !updating() {
+		t.Error("Expected the test package's own -update flag to enable updating")
+	}
+}
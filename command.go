@@ -6,6 +6,10 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
 )
 
 // ActionFunc 命令执行函数签名
@@ -23,13 +27,47 @@ type ActionFunc func(ctx context.Context, cmd *Command) error
 // Command 封装了命令的元数据（名称、描述）、
 // 标志定义和执行逻辑。
 type Command struct {
-	Name         string        // 命令名称（如 "init", "migrate"）
-	Usage        string        // 命令用途简短描述（一行）
-	Description  string        // 命令详细描述（多行）
-	Flags        *flag.FlagSet // 命令标志集（用于定义和解析命令行参数）
-	Action       ActionFunc    // 命令执行函数
-	HideHelpFlag bool          // 是否隐藏 -h 帮助标志
-	appName      string        // 应用名称（用于打印帮助时显示完整用法）
+	Name           string        // 命令名称（如 "init", "migrate"）
+	Usage          string        // 命令用途简短描述（一行）
+	Description    string        // 命令详细描述（多行）
+	Flags          *flag.FlagSet // 命令标志集（用于定义和解析命令行参数）
+	Action         ActionFunc    // 命令执行函数
+	HideHelpFlag   bool          // 是否隐藏 -h 帮助标志
+	Timeout        time.Duration // Action 最长执行时间（0 表示使用 Program.Timeout）
+	HideOutputFlag bool          // 是否不注册 -output/-o 输出格式标志（默认自动注册，配合 Print 使用）
+	Params         []Param       // 声明的标志和位置参数（用于必需检查、交互式询问和帮助）
+	Interactive    bool          // 必需的值缺失且标准输入为终端时，是否交互式询问
+	Pager          bool          // 标准输出为终端时，是否通过分页器显示命令输出（见 Program.EnablePager）
+	NoDryRun       bool          // 命令不支持 --dry-run（指定时拒绝执行，见 Program.EnableDryRun）
+	Dangerous      bool          // 执行 Action 前是否要求用户确认（可通过 --yes/-y 跳过）
+	Confirm        string        // 确认时需要输入其值的标志或位置参数名称（如资源名称），设置后隐含 Dangerous
+	appName        string        // 应用名称（用于打印帮助时显示完整用法）
+
+	processEnv // 环境变量、工作目录、时钟、主机名和用户名（由 Program 设置，默认使用真实的进程状态）
+
+	stdin  io.Reader // 标准输入（默认 os.Stdin）
+	stdout io.Writer // 标准输出（默认 os.Stdout）
+	stderr io.Writer // 标准错误（默认 os.Stderr）
+
+	defaultTimeout time.Duration // 程序全局默认超时（由 Program 设置）
+	timeoutValue   time.Duration // -timeout 标志的值
+	outputValue    string        // -output 标志的值
+	noInput        bool          // 是否通过 --no-input 禁用了交互式询问
+	builtinFlags   []string      // 由框架注册到 Flags 中的标志名称
+	theme          *Theme        // 帮助和错误信息的样式（由 Program 设置）
+	colorMode      string        // --color 标志的值
+	verbosity      int           // -v 标志出现的次数
+	quiet          bool          // 是否指定了 -q
+	logLevel       *slog.Level   // --log-level 标志的值
+	logFormat      string        // --log-format 标志的值
+	logger         *slog.Logger  // 本次调用的日志记录器（启用 EnableLogging 时由 Program 设置）
+	noPager        bool          // 是否通过 --no-pager 禁用了分页器
+	dryRun         *DryRun       // --dry-run 模式下的副作用记录（未指定时为 nil）
+	yes            bool          // 是否通过 --yes 跳过了确认
+	confirmedBy    string        // 危险命令的确认方式（未确认时为空）
+
+	flagState  *flagState    // 标志的共享状态（由 Program 创建，副本之间共享）
+	boundFlags []flagBinding // 本次调用的标志值与原始标志值的对应关系（由 Program 设置）
 }
 
 // NewCommand 创建新命令
@@ -48,6 +86,8 @@ func DefaultHelpCommand() *Command {
 		Usage:       "Show help information",
 		Description: "Display help information for commands",
 		Flags:       flag.NewFlagSet("help", flag.ContinueOnError),
+
+		HideOutputFlag: true,
 	}
 }
 
@@ -58,34 +98,87 @@ func DefaultVersionCommand() *Command {
 		Usage:       "Show version information",
 		Description: "Display the version of this program",
 		Flags:       flag.NewFlagSet("version", flag.ContinueOnError),
+
+		HideOutputFlag: true,
 	}
 }
 
-// SetOutput 设置输出目标
+// SetOutput 设置输出目标（同时作为标准输出和标准错误）
 func (c *Command) SetOutput(w io.Writer) {
-	c.Flags.SetOutput(w)
+	c.SetStdout(w)
+	c.SetStderr(w)
 }
 
-// Output 获取输出目标
+// Output 获取输出目标（即标志解析错误的输出位置）
 func (c *Command) Output() io.Writer {
 	return c.Flags.Output()
 }
 
+// SetStdin 设置标准输入
+func (c *Command) SetStdin(r io.Reader) {
+	c.stdin = r
+}
+
+// Stdin 获取标准输入，如果未设置则返回 os.Stdin
+//
+// Action 应从这里读取输入，而不是直接使用 os.Stdin，以便测试时注入。
+func (c *Command) Stdin() io.Reader {
+	if c.stdin == nil {
+		return os.Stdin
+	}
+	return c.stdin
+}
+
+// SetStdout 设置标准输出
+func (c *Command) SetStdout(w io.Writer) {
+	c.stdout = w
+}
+
+// Stdout 获取标准输出，如果未设置则返回 os.Stdout
+//
+// Action 应将结果写到这里，而不是使用 fmt.Printf，以便测试时捕获。
+func (c *Command) Stdout() io.Writer {
+	if c.stdout == nil {
+		return os.Stdout
+	}
+	return c.stdout
+}
+
+// SetStderr 设置标准错误（同时作为标志解析错误的输出位置）
+func (c *Command) SetStderr(w io.Writer) {
+	c.stderr = w
+	c.Flags.SetOutput(w)
+}
+
+// Stderr 获取标准错误，如果未设置则返回 os.Stderr
+func (c *Command) Stderr() io.Writer {
+	if c.stderr == nil {
+		return os.Stderr
+	}
+	return c.stderr
+}
+
 // SetAppName 设置应用名称（用于打印帮助）
 func (c *Command) SetAppName(name string) {
 	c.appName = name
 }
 
-// PrintUsage 打印命令使用帮助到指定输出
+// PrintUsage 打印命令使用帮助到标准输出
 func (c *Command) PrintUsage() error {
-	w := c.Output()
+	return c.printUsage(c.Stdout())
+}
+
+// printUsage 打印命令使用帮助到 w
+func (c *Command) printUsage(w io.Writer) error {
+	c.setupFlags()
+	st := c.Styles(w)
 	var b []byte
 
 	// 如果有应用名称，显示完整用法
 	if c.appName != "" {
-		b = fmt.Appendf(b, "Usage: %s %s [options]\n\n", c.appName, c.Name)
+		b = fmt.Appendf(b, "%s %s %s [options]%s\n\n", st.Heading.Render("Usage:"), c.appName, st.Command.Render(c.Name), c.usageArgs())
 	} else {
-		b = fmt.Appendf(b, "Usage: %s [options]\n\n", c.Name)
+		b = fmt.Appendf(b, "%s %s [options]%s\n\n", st.Heading.Render("Usage:"), st.Command.Render(c.Name), c.usageArgs())
 	}
 
 	b = fmt.Appendf(b, "%s\n", c.Usage)
@@ -101,14 +194,14 @@ func (c *Command) PrintUsage() error {
 	})
 
 	if hasFlags {
-		b = fmt.Appendln(b, "\nOptions:")
+		b = fmt.Appendf(b, "\n%s\n", st.Heading.Render("Options:"))
 		// 临时使用 bytes.Buffer 来捕获 PrintDefaults 的输出
 		var flagBuf bytes.Buffer
 		oldOutput := c.Flags.Output()
 		c.Flags.SetOutput(&flagBuf)
 		c.Flags.PrintDefaults()
 		c.Flags.SetOutput(oldOutput)
-		b = append(b, flagBuf.Bytes()...)
+		b = append(b, styleFlagDefaults(flagBuf.String(), st.Flag)...)
 	}
 
 	// 一次性写入到 w
@@ -116,41 +209,89 @@ func (c *Command) PrintUsage() error {
 	return err
 }
 
+// setupFlags 注册框架提供的内置标志
+//
+// 命令自己定义了同名标志时，对应的内置标志不会被注册。
+func (c *Command) setupFlags() {
+	c.setupTimeoutFlag()
+	c.setupOutputFlag()
+}
+
+// addBuiltinFlag 记录由框架注册的标志名称
+func (c *Command) addBuiltinFlag(names ...string) {
+	c.builtinFlags = append(c.builtinFlags, names...)
+}
+
+// isBuiltinFlag 判断标志是否由框架注册
+func (c *Command) isBuiltinFlag(name string) bool {
+	return slices.Contains(c.builtinFlags, name)
+}
+
 // Run 执行命令（使用 context.Background()）
 func (c *Command) Run(args []string) error {
 	return c.RunContext(context.Background(), args)
 }
 
 // RunContext 使用指定的 context 执行命令
+//
+// 设置了超时（Command.Timeout 或 Program.Timeout）时，Action 收到的 context
+// 会在超时后以 *TimeoutError 为原因取消，并可通过 -timeout 标志覆盖。
 func (c *Command) RunContext(ctx context.Context, args []string) error {
-	// 设置 Usage 函数
-	if c.HideHelpFlag {
-		// 隐藏帮助时，设置一个空函数来阻止默认 usage 输出
-		c.Flags.Usage = func() {}
-	} else {
-		// 显示帮助时，使用自定义的 PrintUsage
-		c.Flags.Usage = func() {
-			_ = c.PrintUsage() // 忽略错误，因为 flag.Usage 不返回错误
-		}
+	if ok, err := c.prepare(args); !ok {
+		return err
 	}
 
+	// 执行命令
+	if c.Action != nil {
+		return c.runAction(ctx)
+	}
+
+	return nil
+}
+
+// prepare 解析参数、询问缺失的值并确认危险命令，返回是否继续执行 Action
+//
+// 显示帮助或出错时返回 false。
+func (c *Command) prepare(args []string) (bool, error) {
+	c.setupFlags()
+
+	// 阻止 flag 包的默认 usage 输出，解析完成后再根据错误类型决定输出位置
+	c.Flags.Usage = func() {}
+
 	// 解析参数
 	if err := c.Flags.Parse(args); err != nil {
 		// 如果隐藏帮助，直接返回错误（包括 ErrHelp）
 		if c.HideHelpFlag {
-			return err
+			return false, err
 		}
-		// 显示帮助时，ErrHelp 不算错误（因为已经打印了帮助信息）
+		// 显式请求帮助时输出到标准输出，ErrHelp 不算错误
 		if err == flag.ErrHelp {
-			return nil
+			return false, c.PrintUsage()
 		}
-		return err
+		// 标志错误时帮助输出到标准错误
+		_ = c.printUsage(c.Stderr())
+		return false, err
 	}
 
-	// 执行命令
-	if c.Action != nil {
-		return c.Action(ctx, c)
+	// 提前校验输出格式，避免 Action 执行后才发现格式错误
+	if c.outputValue != "" {
+		if _, err := NewFormatter(c.outputValue); err != nil {
+			return false, err
+		}
 	}
 
-	return nil
+	// 检查必需的值，缺失时交互式询问
+	if err := c.resolveParams(); err != nil {
+		return false, err
+	}
+
+	// 更新通过 XxxVar 绑定的变量
+	c.syncBoundFlags()
+
+	// 危险命令在执行前要求确认
+	if err := c.confirm(); err != nil {
+		return false, err
+	}
+
+	return true, nil
 }
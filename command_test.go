@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"os"
 	"strings"
 	"testing"
 )
@@ -276,3 +277,54 @@ func TestCommand_ActionReceivesCommand(t *testing.T) {
 		t.Error("Expected action to receive the same command instance")
 	}
 }
+
+func TestCommand_StdioDefaults(t *testing.T) {
+	cmd := NewCommand("test", "Test command")
+
+	if cmd.Stdin() != os.Stdin {
+		t.Error("Expected Stdin() to default to os.Stdin")
+	}
+	if cmd.Stdout() != os.Stdout {
+		t.Error("Expected Stdout() to default to os.Stdout")
+	}
+	if cmd.Stderr() != os.Stderr {
+		t.Error("Expected Stderr() to default to os.Stderr")
+	}
+}
+
+func TestCommand_HelpGoesToStdout(t *testing.T) {
+	cmd := NewCommand("test", "Test command")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.SetStdout(stdout)
+	cmd.SetStderr(stderr)
+
+	if err := cmd.Run([]string{"-h"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Usage:") {
+		t.Error("Expected help on stdout")
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Expected nothing on stderr, got: %s", stderr.String())
+	}
+}
+
+func TestCommand_FlagErrorGoesToStderr(t *testing.T) {
+	cmd := NewCommand("test", "Test command")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.SetStdout(stdout)
+	cmd.SetStderr(stderr)
+
+	if err := cmd.Run([]string{"-invalid"}); err == nil {
+		t.Fatal("Expected error for invalid flag")
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("Expected nothing on stdout, got: %s", stdout.String())
+	}
+	output := stderr.String()
+	if !strings.Contains(output, "flag provided but not defined") || !strings.Contains(output, "Usage:") {
+		t.Errorf("Expected error and usage on stderr, got: %s", output)
+	}
+}
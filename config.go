@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readConfig 读取配置文件，返回以 "节.键" 为键的配置项
+//
+// 格式与 git config 类似：
+//
+//	# 注释（也可以使用 ;）
+//	[aliases]
+//	ship = deploy --env production --confirm
+//
+//	aliases.st = "status --short"
+//
+// [节] 之后的键自动加上 "节." 前缀；值两端的双引号会按 Go 字符串字面量去除。
+// 文件不存在时返回空配置。
+func readConfig(path string) (map[string]string, error) {
+	config := make(map[string]string)
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("config %s:%d: invalid section %q", path, n, line)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("config %s:%d: expected key = value", path, n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("config %s:%d: invalid quoted value: %w", path, n, err)
+			}
+			value = unquoted
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		config[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
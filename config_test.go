@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	writeFile(t, path, `# comment
+; another comment
+name = top level
+
+[aliases]
+ship = deploy --env production --confirm
+quoted = "say \"hi\""
+
+[ other ]
+key=value
+aliases.st = status
+`)
+
+	config, err := readConfig(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := map[string]string{
+		"name":             "top level",
+		"aliases.ship":     "deploy --env production --confirm",
+		"aliases.quoted":   `say "hi"`,
+		"other.key":        "value",
+		"other.aliases.st": "status",
+	}
+	if len(config) != len(want) {
+		t.Errorf("Expected %d entries, got %v", len(want), config)
+	}
+	for key, value := range want {
+		if config[key] != value {
+			t.Errorf("config[%q] = %q, want %q", key, config[key], value)
+		}
+	}
+}
+
+func TestReadConfigMissing(t *testing.T) {
+	config, err := readConfig(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(config) != 0 {
+		t.Errorf("Expected empty config for missing file, got %v (%v)", config, err)
+	}
+}
+
+func TestReadConfigErrors(t *testing.T) {
+	tests := map[string]string{
+		"[aliases\n":          "config.txt:1: invalid section",
+		"ok = 1\njust text\n": "config.txt:2: expected key = value",
+		"a = \"bad\\q\"\n":    "config.txt:1: invalid quoted value",
+	}
+	for content, want := range tests {
+		path := filepath.Join(t.TempDir(), "config.txt")
+		writeFile(t, path, content)
+		if _, err := readConfig(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package cli
+
+import "fmt"
+
+// 确认方式（记录在日志和审计记录中）
+const (
+	confirmedByPrompt = "prompt" // 用户在终端上确认
+	confirmedByFlag   = "flag"   // 通过 --yes 跳过确认
+)
+
+// ConfirmError 表示危险命令没有得到确认
+type ConfirmError struct {
+	Command string // 命令名称
+	Reason  string // 原因
+}
+
+// Error 实现 error 接口
+func (e *ConfirmError) Error() string {
+	return fmt.Sprintf("command %s was not confirmed: %s", e.Command, e.Reason)
+}
+
+// yesFlag 返回 --yes/-y 全局标志
+func yesFlag() globalFlag {
+	return globalFlag{
+		name:  "yes",
+		short: "y",
+		usage: "Skip confirmation of dangerous commands",
+		apply: func(inv *Command, val string) error {
+			v, err := parseBoolFlag(val)
+			inv.yes = v
+			return err
+		},
+	}
+}
+
+// hasDangerousCommand 判断是否有命令需要确认
+func (p *Program) hasDangerousCommand() bool {
+	for _, cmd := range p.Commands {
+		if cmd.needsConfirm() {
+			return true
+		}
+	}
+	return false
+}
+
+// needsConfirm 判断执行 Action 前是否需要确认
+func (c *Command) needsConfirm() bool {
+	return c.Dangerous || c.Confirm != ""
+}
+
+// confirm 在执行危险命令前要求用户确认
+//
+// 指定了 --yes 或 --dry-run 时不询问；标准输入不是终端或指定了 --no-input 时返回 *ConfirmError。
+// Confirm 指定了参数时，用户需要输入该参数的值（如要删除的资源名称）；否则或参数值为空时回答 y/N。
+func (c *Command) confirm() error {
+	if !c.needsConfirm() || c.IsDryRun() {
+		return nil
+	}
+	if c.yes {
+		c.confirmed(confirmedByFlag)
+		return nil
+	}
+	if c.noInput || !isTerminal(c.Stdin()) {
+		return &ConfirmError{Command: c.Name, Reason: "stdin is not a terminal (use --yes to confirm)"}
+	}
+
+	name, err := c.confirmValue()
+	if err != nil {
+		return err
+	}
+	pr := c.Prompter()
+	if name == "" {
+		ok, err := pr.Confirm(fmt.Sprintf("Command %s is dangerous. Continue?", c.Name), false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return &ConfirmError{Command: c.Name, Reason: "declined"}
+		}
+	} else {
+		pr.printf("Command %s is dangerous and cannot be undone.\n", c.Name)
+		typed, err := pr.Text(fmt.Sprintf("Type %q to confirm", name), "")
+		if err != nil {
+			return err
+		}
+		if typed != name {
+			return &ConfirmError{Command: c.Name, Reason: fmt.Sprintf("typed %q, expected %q", typed, name)}
+		}
+	}
+	c.confirmed(confirmedByPrompt)
+	return nil
+}
+
+// confirmValue 返回确认时需要输入的值，Confirm 为空时返回空字符串
+//
+// Confirm 可以是标志名称，也可以是 Params 中位置参数的名称。
+func (c *Command) confirmValue() (string, error) {
+	if c.Confirm == "" {
+		return "", nil
+	}
+	if f := c.Flags.Lookup(c.Confirm); f != nil {
+		return f.Value.String(), nil
+	}
+	pos := 0
+	for _, p := range c.Params {
+		if !p.Positional {
+			continue
+		}
+		if p.Name == c.Confirm {
+			return c.Flags.Arg(pos), nil
+		}
+		pos++
+	}
+	return "", fmt.Errorf("confirm %q does not match any flag or argument of command %q", c.Confirm, c.Name)
+}
+
+// confirmed 记录确认方式
+func (c *Command) confirmed(by string) {
+	c.confirmedBy = by
+	c.Logger().Info("dangerous command confirmed", "command", c.Name, "by", by)
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newConfirmProgram 创建包含危险命令的程序，返回标准错误和执行次数
+func newConfirmProgram() (*Program, *bytes.Buffer, *int) {
+	prog := NewProgram("testapp", "1.0.0")
+	stderr := &bytes.Buffer{}
+	prog.SetStdout(&bytes.Buffer{})
+	prog.SetStderr(stderr)
+
+	runs := new(int)
+	action := func(ctx context.Context, cmd *Command) error {
+		*runs++
+		return nil
+	}
+
+	purgeCmd := NewCommand("purge", "Purge all caches")
+	purgeCmd.Dangerous = true
+	purgeCmd.Action = action
+
+	dropCmd := NewCommand("drop", "Drop a database")
+	dropCmd.Confirm = "database"
+	dropCmd.Params = []Param{{Name: "database", Positional: true, Required: true}}
+	dropCmd.Action = action
+
+	deleteCmd := NewCommand("delete", "Delete a bucket")
+	deleteCmd.Flags.String("bucket", "", "Bucket name")
+	deleteCmd.Confirm = "bucket"
+	deleteCmd.Action = action
+
+	prog.Commands = []*Command{purgeCmd, dropCmd, deleteCmd, NewCommand("noop", "Do nothing")}
+	return prog, stderr, runs
+}
+
+func TestCommand_ConfirmPrompt(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		input string
+		runs  int
+		err   string
+	}{
+		{"yes", []string{"purge"}, "y\n", 1, ""},
+		{"default no", []string{"purge"}, "\n", 0, "command purge was not confirmed: declined"},
+		{"typed name", []string{"drop", "prod"}, "prod\n", 1, ""},
+		{"wrong name", []string{"drop", "prod"}, "staging\n", 0, `command drop was not confirmed: typed "staging", expected "prod"`},
+		{"flag value", []string{"delete", "-bucket", "logs"}, "logs\n", 1, ""},
+		{"empty flag value", []string{"delete"}, "yes\n", 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, stderr, runs := newConfirmProgram()
+			prog.SetStdin(ttyReader{strings.NewReader(tt.input)})
+
+			err := prog.Run(append([]string{"testapp"}, tt.args...))
+			if tt.err == "" && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tt.err != "" {
+				var ce *ConfirmError
+				if !errors.As(err, &ce) || err.Error() != tt.err {
+					t.Errorf("Expected error %q, got %v", tt.err, err)
+				}
+			}
+			if *runs != tt.runs {
+				t.Errorf("Expected %d runs, got %d", tt.runs, *runs)
+			}
+			if stderr.Len() == 0 {
+				t.Error("Expected a confirmation prompt")
+			}
+		})
+	}
+}
+
+func TestCommand_ConfirmPromptText(t *testing.T) {
+	prog, stderr, _ := newConfirmProgram()
+	prog.SetStdin(ttyReader{strings.NewReader("prod\n")})
+
+	if err := prog.Run([]string{"testapp", "drop", "prod"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "Command drop is dangerous and cannot be undone.\nType \"prod\" to confirm: "
+	if stderr.String() != want {
+		t.Errorf("Unexpected prompt: %q", stderr.String())
+	}
+}
+
+func TestCommand_ConfirmYes(t *testing.T) {
+	for _, args := range [][]string{
+		{"testapp", "purge", "--yes"},
+		{"testapp", "-y", "drop", "prod"},
+	} {
+		prog, stderr, runs := newConfirmProgram()
+		// 标准输入不是终端，--yes 跳过确认
+		prog.SetStdin(strings.NewReader(""))
+		if err := prog.Run(args); err != nil {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+		if *runs != 1 || stderr.Len() != 0 {
+			t.Errorf("%v: expected to run without prompting, got %d runs and %q", args, *runs, stderr.String())
+		}
+	}
+}
+
+func TestCommand_ConfirmNotTerminal(t *testing.T) {
+	prog, _, runs := newConfirmProgram()
+	prog.SetStdin(strings.NewReader("y\n"))
+
+	err := prog.Run([]string{"testapp", "purge"})
+	var ce *ConfirmError
+	if !errors.As(err, &ce) || !strings.Contains(err.Error(), "use --yes to confirm") {
+		t.Errorf("Expected a ConfirmError, got %v", err)
+	}
+	if *runs != 0 {
+		t.Errorf("Expected purge not to run, got %d runs", *runs)
+	}
+
+	// --no-input 同样拒绝询问
+	prog.Commands[0].Interactive = true
+	prog.SetStdin(ttyReader{strings.NewReader("y\n")})
+	if err := prog.Run([]string{"testapp", "purge", "--no-input"}); !errors.As(err, &ce) {
+		t.Errorf("Expected a ConfirmError with --no-input, got %v", err)
+	}
+	if *runs != 0 {
+		t.Errorf("Expected purge not to run, got %d runs", *runs)
+	}
+}
+
+func TestCommand_ConfirmDryRun(t *testing.T) {
+	prog, _, runs := newConfirmProgram()
+	prog.EnableDryRun = true
+	prog.SetStdin(strings.NewReader(""))
+
+	if err := prog.Run([]string{"testapp", "purge", "--dry-run"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *runs != 1 {
+		t.Errorf("Expected dry run without confirmation, got %d runs", *runs)
+	}
+}
+
+func TestCommand_ConfirmLogged(t *testing.T) {
+	prog, stderr, _ := newConfirmProgram()
+	prog.EnableLogging = true
+
+	if err := prog.Run([]string{"testapp", "purge", "--yes", "-v"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stderr.String() != "level=INFO msg=\"dangerous command confirmed\" command=purge by=flag\n" {
+		t.Errorf("Unexpected log: %q", stderr.String())
+	}
+}
+
+func TestCommand_ConfirmUnknownParam(t *testing.T) {
+	cmd := NewCommand("wipe", "Wipe")
+	cmd.Confirm = "target"
+	cmd.Action = func(ctx context.Context, cmd *Command) error { return nil }
+	cmd.SetStdin(ttyReader{strings.NewReader("")})
+	cmd.SetOutput(&bytes.Buffer{})
+
+	err := cmd.Run(nil)
+	if err == nil || err.Error() != `confirm "target" does not match any flag or argument of command "wipe"` {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestProgram_YesGlobalFlag(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	out := &bytes.Buffer{}
+	prog.SetOutput(out)
+	prog.Commands = []*Command{NewCommand("noop", "Do nothing")}
+
+	if err := prog.PrintUsage(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "--yes") {
+		t.Errorf("Expected no --yes flag without dangerous commands, got:\n%s", out.String())
+	}
+
+	prog.Commands[0].Dangerous = true
+	out.Reset()
+	if err := prog.PrintUsage(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "-y, --yes    Skip confirmation of dangerous commands") {
+		t.Errorf("Expected --yes flag, got:\n%s", out.String())
+	}
+}
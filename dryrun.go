@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+)
+
+// dryRunKey context 中存放 dry-run 记录的键
+type dryRunKey struct{}
+
+// DryRun 记录 dry-run 模式下本应执行的副作用
+//
+// nil 表示未启用 dry-run：Would 返回 false，调用方应实际执行操作。方法可以在多个 goroutine 中并发调用。
+type DryRun struct {
+	mu      sync.Mutex
+	actions []string
+}
+
+// Would 在 dry-run 模式下记录一项本应执行的操作（如 "delete %s"）并返回 true，调用方应跳过该操作；
+// 未启用 dry-run 时不记录并返回 false
+//
+//	if cmd.Would("delete %s", path) {
+//		continue
+//	}
+//	os.Remove(path)
+func (d *DryRun) Would(format string, args ...any) bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.actions = append(d.actions, fmt.Sprintf(format, args...))
+	return true
+}
+
+// Actions 返回已记录的操作
+func (d *DryRun) Actions() []string {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.actions)
+}
+
+// writeSummary 输出已记录操作的汇总
+func (d *DryRun) writeSummary(w io.Writer) error {
+	actions := d.Actions()
+	var b []byte
+	switch len(actions) {
+	case 0:
+		b = fmt.Appendln(b, "Dry run: no changes would be made")
+	case 1:
+		b = fmt.Appendln(b, "Dry run: 1 change would be made:")
+	default:
+		b = fmt.Appendf(b, "Dry run: %d changes would be made:\n", len(actions))
+	}
+	for _, action := range actions {
+		b = fmt.Appendf(b, "  - would %s\n", action)
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// DryRunFrom 返回 context 中的 dry-run 记录，未指定 --dry-run 时返回 nil
+//
+// 返回值可以直接调用 Would，因此不持有 *Command 的函数也能检查并记录副作用：
+//
+//	if cli.DryRunFrom(ctx).Would("drop table %s", name) {
+//		return nil
+//	}
+func DryRunFrom(ctx context.Context) *DryRun {
+	d, _ := ctx.Value(dryRunKey{}).(*DryRun)
+	return d
+}
+
+// IsDryRun 判断本次调用是否指定了 --dry-run
+func (c *Command) IsDryRun() bool {
+	return c.dryRun != nil
+}
+
+// Would 在 dry-run 模式下记录一项本应执行的操作并返回 true，否则返回 false（见 DryRun.Would）
+func (c *Command) Would(format string, args ...any) bool {
+	return c.dryRun.Would(format, args...)
+}
+
+// dryRunFlag 返回 --dry-run 全局标志
+func dryRunFlag() globalFlag {
+	return globalFlag{
+		name:  "dry-run",
+		usage: "Show what would be done without making any changes",
+		apply: func(inv *Command, val string) error {
+			v, err := parseBoolFlag(val)
+			inv.dryRun = nil
+			if v {
+				inv.dryRun = &DryRun{}
+			}
+			return err
+		},
+	}
+}
+
+// runDryRun 以 dry-run 模式执行 run，并在结束后于标准错误输出汇总
+//
+// 声明了 NoDryRun 的命令拒绝执行。
+func (c *Command) runDryRun(ctx context.Context, run func(ctx context.Context) error) error {
+	if c.NoDryRun {
+		return fmt.Errorf("command %s does not support --dry-run", c.Name)
+	}
+	err := run(context.WithValue(ctx, dryRunKey{}, c.dryRun))
+	if werr := c.dryRun.writeSummary(c.Stderr()); err == nil {
+		err = werr
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// newDryRunProgram 创建启用 dry-run 的程序，clean 命令删除 removed 中记录的文件
+func newDryRunProgram() (*Program, *bytes.Buffer, *bytes.Buffer, *[]string) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.EnableDryRun = true
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	prog.SetStdout(stdout)
+	prog.SetStderr(stderr)
+
+	removed := &[]string{}
+	cleanCmd := NewCommand("clean", "Remove build artifacts")
+	cleanCmd.Action = func(ctx context.Context, cmd *Command) error {
+		for _, name := range []string{"bin/app", "bin/app.old"} {
+			if cmd.Would("delete %s", name) {
+				continue
+			}
+			*removed = append(*removed, name)
+		}
+		// 不持有 Command 的函数通过 context 记录
+		if !DryRunFrom(ctx).Would("prune cache") {
+			*removed = append(*removed, "cache")
+		}
+		return nil
+	}
+
+	migrateCmd := NewCommand("migrate", "Run database migrations")
+	migrateCmd.NoDryRun = true
+	migrateCmd.Action = func(ctx context.Context, cmd *Command) error {
+		*removed = append(*removed, "migrated")
+		return nil
+	}
+
+	statusCmd := NewCommand("status", "Show status")
+	statusCmd.Action = func(ctx context.Context, cmd *Command) error {
+		if cmd.IsDryRun() {
+			_, err := cmd.Stdout().Write([]byte("dry run\n"))
+			return err
+		}
+		return nil
+	}
+	prog.Commands = []*Command{cleanCmd, migrateCmd, statusCmd}
+	return prog, stdout, stderr, removed
+}
+
+func TestDryRun_Nil(t *testing.T) {
+	var d *DryRun
+	if d.Would("delete %s", "x") {
+		t.Error("Expected nil DryRun not to record")
+	}
+	if d.Actions() != nil {
+		t.Errorf("Expected no actions, got %v", d.Actions())
+	}
+	if DryRunFrom(context.Background()) != nil {
+		t.Error("Expected no DryRun in an empty context")
+	}
+}
+
+func TestProgram_DryRun(t *testing.T) {
+	prog, _, stderr, removed := newDryRunProgram()
+
+	if err := prog.Run([]string{"testapp", "clean", "--dry-run"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*removed) != 0 {
+		t.Errorf("Expected no side effects, got %v", *removed)
+	}
+	want := "Dry run: 3 changes would be made:\n" +
+		"  - would delete bin/app\n" +
+		"  - would delete bin/app.old\n" +
+		"  - would prune cache\n"
+	if stderr.String() != want {
+		t.Errorf("Unexpected summary:\n%s", stderr.String())
+	}
+
+	// 标志值不会泄漏到下一次调用
+	stderr.Reset()
+	if err := prog.Run([]string{"testapp", "clean"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(*removed, ",") != "bin/app,bin/app.old,cache" {
+		t.Errorf("Unexpected side effects: %v", *removed)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Expected no summary without --dry-run, got:\n%s", stderr.String())
+	}
+}
+
+func TestProgram_DryRunSummary(t *testing.T) {
+	prog, stdout, stderr, _ := newDryRunProgram()
+
+	if err := prog.Run([]string{"testapp", "--dry-run", "status"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stdout.String() != "dry run\n" {
+		t.Errorf("Expected IsDryRun to be true, got output %q", stdout.String())
+	}
+	if stderr.String() != "Dry run: no changes would be made\n" {
+		t.Errorf("Unexpected summary: %q", stderr.String())
+	}
+
+	var buf bytes.Buffer
+	d := &DryRun{}
+	d.Would("restart %s", "web")
+	if err := d.writeSummary(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "Dry run: 1 change would be made:\n  - would restart web\n" {
+		t.Errorf("Unexpected summary: %q", buf.String())
+	}
+}
+
+func TestProgram_DryRunNotSupported(t *testing.T) {
+	prog, _, stderr, removed := newDryRunProgram()
+
+	err := prog.Run([]string{"testapp", "migrate", "--dry-run"})
+	if err == nil || err.Error() != "command migrate does not support --dry-run" {
+		t.Errorf("Expected dry-run to be refused, got %v", err)
+	}
+	if len(*removed) != 0 || stderr.Len() != 0 {
+		t.Errorf("Expected migrate not to run, got side effects %v and output %q", *removed, stderr.String())
+	}
+
+	if err := prog.Run([]string{"testapp", "migrate", "--dry-run=false"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*removed) != 1 {
+		t.Errorf("Expected migrate to run, got %v", *removed)
+	}
+}
+
+func TestProgram_DryRunDisabled(t *testing.T) {
+	prog, _, _, removed := newDryRunProgram()
+	prog.EnableDryRun = false
+
+	if err := prog.Run([]string{"testapp", "clean", "--dry-run"}); err == nil {
+		t.Error("Expected --dry-run to be an unknown flag")
+	}
+	if len(*removed) != 0 {
+		t.Errorf("Expected clean not to run, got %v", *removed)
+	}
+
+	out := &bytes.Buffer{}
+	prog.SetStdout(out)
+	if err := prog.PrintUsage(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "--dry-run") {
+		t.Errorf("Expected no --dry-run flag, got:\n%s", out.String())
+	}
+}
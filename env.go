@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// processEnv 程序和命令读取的进程状态：环境变量、工作目录、时钟、主机名和用户名
+//
+// 未设置时使用真实的进程状态；测试时可以替换，避免读取或依赖真实环境（见 clitest 包）。
+// Program 和 Command 都内嵌了 processEnv，Program 执行命令时会将自己的设置传给命令。
+type processEnv struct {
+	environ    []string         // 环境变量（KEY=value）
+	hasEnviron bool             // 是否设置了 environ
+	dir        string           // 工作目录
+	clock      func() time.Time // 当前时间
+	hostname   string           // 主机名
+	username   string           // 当前用户名
+}
+
+// SetEnviron 设置环境变量（KEY=value 格式，同名时以后出现的为准）
+func (e *processEnv) SetEnviron(env []string) {
+	e.environ = slices.Clone(env)
+	e.hasEnviron = true
+}
+
+// Environ 获取环境变量，如果未设置则返回 os.Environ()
+func (e *processEnv) Environ() []string {
+	if !e.hasEnviron {
+		return os.Environ()
+	}
+	return slices.Clone(e.environ)
+}
+
+// LookupEnv 获取环境变量的值，如果未设置环境变量则读取进程的环境变量
+func (e *processEnv) LookupEnv(key string) (string, bool) {
+	if !e.hasEnviron {
+		return os.LookupEnv(key)
+	}
+	for i := len(e.environ) - 1; i >= 0; i-- {
+		if k, v, ok := strings.Cut(e.environ[i], "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Getenv 获取环境变量的值，不存在时返回空字符串
+func (e *processEnv) Getenv(key string) string {
+	v, _ := e.LookupEnv(key)
+	return v
+}
+
+// SetDir 设置工作目录（相对路径基于此目录解析）
+func (e *processEnv) SetDir(dir string) {
+	e.dir = dir
+}
+
+// Dir 获取工作目录，如果未设置则返回进程的当前目录
+func (e *processEnv) Dir() string {
+	if e.dir == "" {
+		dir, _ := os.Getwd()
+		return dir
+	}
+	return e.dir
+}
+
+// SetClock 设置获取当前时间的函数
+func (e *processEnv) SetClock(now func() time.Time) {
+	e.clock = now
+}
+
+// Now 获取当前时间，如果未设置时钟则返回 time.Now()
+func (e *processEnv) Now() time.Time {
+	if e.clock == nil {
+		return time.Now()
+	}
+	return e.clock()
+}
+
+// SetHostname 设置主机名
+func (e *processEnv) SetHostname(name string) {
+	e.hostname = name
+}
+
+// Hostname 获取主机名，如果未设置则返回 os.Hostname()（失败时为空字符串）
+func (e *processEnv) Hostname() string {
+	if e.hostname == "" {
+		name, _ := os.Hostname()
+		return name
+	}
+	return e.hostname
+}
+
+// SetUsername 设置当前用户名
+func (e *processEnv) SetUsername(name string) {
+	e.username = name
+}
+
+// Username 获取当前用户名
+//
+// 未设置时使用 user.Current()，无法确定时依次使用 USER 和 USERNAME 环境变量。
+func (e *processEnv) Username() string {
+	if e.username != "" {
+		return e.username
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := e.Getenv("USER"); name != "" {
+		return name
+	}
+	return e.Getenv("USERNAME")
+}
+
+// Path 将相对路径解析为基于工作目录的路径，绝对路径原样返回
+func (e *processEnv) Path(name string) string {
+	if e.dir == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(e.dir, name)
+}
+
+// userCacheDir 按 os.UserCacheDir 的规则，使用设置的环境变量获取用户缓存目录
+func (e *processEnv) userCacheDir() string {
+	switch runtime.GOOS {
+	case "windows":
+		return e.Getenv("LocalAppData")
+	case "darwin", "ios":
+		if home := e.Getenv("HOME"); home != "" {
+			return filepath.Join(home, "Library", "Caches")
+		}
+	case "plan9":
+		if home := e.Getenv("home"); home != "" {
+			return filepath.Join(home, "lib", "cache")
+		}
+	default:
+		if dir := e.Getenv("XDG_CACHE_HOME"); filepath.IsAbs(dir) {
+			return dir
+		}
+		if home := e.Getenv("HOME"); home != "" {
+			return filepath.Join(home, ".cache")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessEnv_Defaults(t *testing.T) {
+	t.Setenv("CLI_TEST_ENV", "real")
+	var e processEnv
+
+	if e.Getenv("CLI_TEST_ENV") != "real" {
+		t.Error("Expected real environment by default")
+	}
+	if !slices.Contains(e.Environ(), "CLI_TEST_ENV=real") {
+		t.Error("Expected Environ to return os.Environ by default")
+	}
+	if wd, _ := os.Getwd(); e.Dir() != wd {
+		t.Errorf("Expected current directory, got %q", e.Dir())
+	}
+	if e.Path("a/b") != "a/b" {
+		t.Errorf("Expected relative path unchanged, got %q", e.Path("a/b"))
+	}
+	if time.Since(e.Now()) > time.Minute {
+		t.Error("Expected real clock by default")
+	}
+	if hostname, _ := os.Hostname(); e.Hostname() != hostname {
+		t.Errorf("Expected real hostname, got %q", e.Hostname())
+	}
+}
+
+func TestProcessEnv_Injected(t *testing.T) {
+	t.Setenv("CLI_TEST_ENV", "real")
+	var e processEnv
+	e.SetEnviron([]string{"A=1", "B=x=y", "A=2", "INVALID"})
+	e.SetDir("/work")
+	fixed := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	e.SetClock(func() time.Time { return fixed })
+	e.SetHostname("build-01")
+	e.SetUsername("ada")
+
+	if e.Getenv("A") != "2" || e.Getenv("B") != "x=y" {
+		t.Errorf("Unexpected values: A=%q B=%q", e.Getenv("A"), e.Getenv("B"))
+	}
+	if _, ok := e.LookupEnv("CLI_TEST_ENV"); ok {
+		t.Error("Expected process environment to be hidden")
+	}
+	if e.Dir() != "/work" || e.Path("a") != filepath.Join("/work", "a") {
+		t.Errorf("Unexpected dir %q / path %q", e.Dir(), e.Path("a"))
+	}
+	if e.Path(filepath.Join(string(filepath.Separator), "abs")) != filepath.Join(string(filepath.Separator), "abs") {
+		t.Error("Expected absolute path unchanged")
+	}
+	if !e.Now().Equal(fixed) {
+		t.Errorf("Expected fake clock, got %v", e.Now())
+	}
+	if e.Hostname() != "build-01" || e.Username() != "ada" {
+		t.Errorf("Unexpected hostname %q / username %q", e.Hostname(), e.Username())
+	}
+
+	env := e.Environ()
+	env[0] = "changed"
+	if e.Environ()[0] != "A=1" {
+		t.Error("Expected Environ to return a copy")
+	}
+}
+
+func TestProcessEnv_UserCacheDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG rules apply on linux")
+	}
+	var e processEnv
+	e.SetEnviron([]string{"HOME=/home/ada"})
+	if got := e.userCacheDir(); got != "/home/ada/.cache" {
+		t.Errorf("Expected HOME based cache dir, got %q", got)
+	}
+	e.SetEnviron([]string{"HOME=/home/ada", "XDG_CACHE_HOME=/xdg"})
+	if got := e.userCacheDir(); got != "/xdg" {
+		t.Errorf("Expected XDG_CACHE_HOME, got %q", got)
+	}
+	e.SetEnviron(nil)
+	if got := e.userCacheDir(); got != "" {
+		t.Errorf("Expected empty cache dir without HOME, got %q", got)
+	}
+}
+
+func TestProgram_ProcessEnvPassedToCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "steps.txt"), "show $GREETING\n")
+
+	prog := NewProgram("testapp", "1.0.0")
+	prog.EnableScript = true
+	prog.SetOutput(&strings.Builder{})
+	prog.SetEnviron([]string{"GREETING=hi"})
+	prog.SetDir(dir)
+	fixed := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	prog.SetClock(func() time.Time { return fixed })
+
+	var got []string
+	showCmd := NewCommand("show", "Show")
+	showCmd.Action = func(ctx context.Context, cmd *Command) error {
+		got = append(cmd.Flags.Args(), cmd.Getenv("GREETING"), cmd.Dir(), cmd.Now().Format(time.RFC3339))
+		return nil
+	}
+	prog.Commands = []*Command{showCmd}
+
+	// 相对路径基于设置的工作目录，脚本变量来自设置的环境变量
+	if err := prog.Run([]string{"testapp", "run-script", "-q", "steps.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"hi", "hi", dir, "2024-01-02T03:04:05Z"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,27 @@
+package cli
+
+import "errors"
+
+// ExitCoder 由携带进程退出码的错误实现
+type ExitCoder interface {
+	ExitCode() int
+}
+
+// ExitCode 返回 err 对应的进程退出码
+//
+// nil 返回 0；错误链中实现了 ExitCoder 的错误返回其退出码；其它错误返回 1。
+//
+//	if err := app.Run(os.Args); err != nil {
+//		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+//		os.Exit(cli.ExitCode(err))
+//	}
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var ec ExitCoder
+	if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+	return 1
+}
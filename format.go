@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// Format 输出格式名称
+type Format string
+
+// 内置的输出格式
+const (
+	FormatTable     Format = "table"    // 对齐的表格（默认用于终端）
+	FormatJSON      Format = "json"     // 缩进的 JSON（默认用于管道和文件）
+	FormatJSONLines Format = "jsonl"    // 每行一个 JSON 值
+	FormatCSV       Format = "csv"      // CSV，首行为表头
+	FormatTemplate  Format = "template" // Go 模板，写作 template=<模板>
+)
+
+// Formatter 将任意值渲染到输出
+type Formatter interface {
+	Format(w io.Writer, v any) error
+}
+
+// FormatterFunc 函数形式的 Formatter
+type FormatterFunc func(w io.Writer, v any) error
+
+// Format 实现 Formatter 接口
+func (f FormatterFunc) Format(w io.Writer, v any) error {
+	return f(w, v)
+}
+
+// NewFormatter 根据格式描述创建 Formatter
+//
+// spec 可以是 table、json、jsonl、csv，或 template=<Go 模板>（如 template={{.Name}}）。
+func NewFormatter(spec string) (Formatter, error) {
+	name, arg, hasArg := strings.Cut(spec, "=")
+	switch Format(name) {
+	case FormatTable:
+		return FormatterFunc(formatTable), nil
+	case FormatJSON:
+		return FormatterFunc(formatJSON), nil
+	case FormatJSONLines:
+		return FormatterFunc(formatJSONLines), nil
+	case FormatCSV:
+		return FormatterFunc(formatCSV), nil
+	case FormatTemplate:
+		if !hasArg || arg == "" {
+			return nil, fmt.Errorf("output format %q requires a template, e.g. template={{.Name}}", name)
+		}
+		tmpl, err := template.New("output").Funcs(template.FuncMap{"json": toJSON}).Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output template: %w", err)
+		}
+		return FormatterFunc(func(w io.Writer, v any) error {
+			return tmpl.Execute(w, v)
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown output format %q (valid: table, json, jsonl, csv, template=...)", spec)
+}
+
+// setupOutputFlag 注册 -output/-o 标志（设置了 HideOutputFlag 或命令自己定义了 output 标志时不注册）
+func (c *Command) setupOutputFlag() {
+	if c.HideOutputFlag || c.Flags.Lookup("output") != nil {
+		return
+	}
+	c.Flags.StringVar(&c.outputValue, "output", "", "Output format: table, json, jsonl, csv or template=<tmpl> (default table on a terminal, json otherwise)")
+	c.addBuiltinFlag("output")
+	if c.Flags.Lookup("o") == nil {
+		c.Flags.StringVar(&c.outputValue, "o", "", "Shorthand for -output")
+		c.addBuiltinFlag("o")
+	}
+}
+
+// OutputFormat 返回本次调用生效的输出格式描述
+//
+// 优先使用 -output 标志的值；未指定时，标准输出为终端则使用 table，否则使用 json。
+func (c *Command) OutputFormat() string {
+	if c.outputValue != "" {
+		return c.outputValue
+	}
+	if isTerminal(c.Stdout()) {
+		return string(FormatTable)
+	}
+	return string(FormatJSON)
+}
+
+// Print 按本次调用的输出格式将 v 写到标准输出
+//
+// v 可以是结构体、结构体切片、map、*Table 或基本类型的值；
+// 表格和 CSV 的列名取自字段的 json 标签（没有标签时使用字段名）。
+func (c *Command) Print(v any) error {
+	f, err := NewFormatter(c.OutputFormat())
+	if err != nil {
+		return err
+	}
+	return f.Format(c.Stdout(), v)
+}
+
+// formatJSON 以缩进的 JSON 输出
+func formatJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// formatJSONLines 每行输出一个 JSON 值，切片按元素拆分
+func formatJSONLines(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	if t, ok := asTable(v); ok {
+		for _, row := range t.records() {
+			if err := enc.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	rv := indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return enc.Encode(v)
+	}
+	for i := range rv.Len() {
+		if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatCSV 以 CSV 输出，首行为表头
+func formatCSV(w io.Writer, v any) error {
+	t := tabulate(v)
+	cw := csv.NewWriter(w)
+	if len(t.Header) > 0 {
+		if err := cw.Write(t.Header); err != nil {
+			return err
+		}
+	}
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// formatTable 以对齐的表格输出
+func formatTable(w io.Writer, v any) error {
+	return tabulate(v).write(w)
+}
+
+// toJSON 模板函数，将值编码为单行 JSON
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
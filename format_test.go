@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type formatItem struct {
+	Name   string `json:"name"`
+	Count  int    `json:"count"`
+	Secret string `json:"-"`
+}
+
+func TestNewFormatter(t *testing.T) {
+	valid := []string{"table", "json", "jsonl", "csv", "template={{.Name}}"}
+	for _, spec := range valid {
+		if _, err := NewFormatter(spec); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", spec, err)
+		}
+	}
+
+	invalid := []string{"xml", "template", "template=", "template={{.Name"}
+	for _, spec := range invalid {
+		if _, err := NewFormatter(spec); err == nil {
+			t.Errorf("Expected %q to be invalid", spec)
+		}
+	}
+}
+
+func TestFormatter_Outputs(t *testing.T) {
+	items := []formatItem{{Name: "alpha", Count: 1, Secret: "x"}, {Name: "beta", Count: 22}}
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"table", "NAME    COUNT\nalpha   1\nbeta    22\n"},
+		{"json", "[\n  {\n    \"name\": \"alpha\",\n    \"count\": 1\n  },\n  {\n    \"name\": \"beta\",\n    \"count\": 22\n  }\n]\n"},
+		{"jsonl", "{\"name\":\"alpha\",\"count\":1}\n{\"name\":\"beta\",\"count\":22}\n"},
+		{"csv", "NAME,COUNT\nalpha,1\nbeta,22\n"},
+		{"template={{range .}}{{.Name}}={{.Count}};{{end}}", "alpha=1;beta=22;"},
+	}
+
+	for _, tt := range tests {
+		f, err := NewFormatter(tt.spec)
+		if err != nil {
+			t.Fatalf("NewFormatter(%q) failed: %v", tt.spec, err)
+		}
+		buf := &bytes.Buffer{}
+		if err := f.Format(buf, items); err != nil {
+			t.Fatalf("Format(%q) failed: %v", tt.spec, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.spec, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestFormatter_Table(t *testing.T) {
+	table := &Table{
+		Header: []string{"ID", "STATUS"},
+		Rows:   [][]string{{"1", "ok"}, {"2", "failed"}},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := formatJSONLines(buf, table); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\"ID\":\"1\",\"STATUS\":\"ok\"}\n{\"ID\":\"2\",\"STATUS\":\"failed\"}\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestCommand_OutputFlag(t *testing.T) {
+	cmd := NewCommand("list", "List items")
+	buf := &bytes.Buffer{}
+	cmd.SetOutput(buf)
+	cmd.Action = func(ctx context.Context, c *Command) error {
+		return c.Print([]formatItem{{Name: "alpha", Count: 1}})
+	}
+
+	if err := cmd.Run([]string{"-o", "csv"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if buf.String() != "NAME,COUNT\nalpha,1\n" {
+		t.Errorf("Expected CSV output, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := cmd.Run([]string{"--output=template={{range .}}{{.Name}}{{end}}"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if buf.String() != "alpha" {
+		t.Errorf("Expected template output, got %q", buf.String())
+	}
+}
+
+func TestCommand_OutputFlagInvalid(t *testing.T) {
+	cmd := NewCommand("list", "List items")
+	cmd.SetOutput(&bytes.Buffer{})
+	executed := false
+	cmd.Action = func(ctx context.Context, c *Command) error {
+		executed = true
+		return nil
+	}
+
+	err := cmd.Run([]string{"-o", "xml"})
+	if err == nil || !strings.Contains(err.Error(), "unknown output format") {
+		t.Errorf("Expected unknown output format error, got %v", err)
+	}
+	if executed {
+		t.Error("Expected action not to run with an invalid output format")
+	}
+}
+
+func TestCommand_OutputFormatDefault(t *testing.T) {
+	cmd := NewCommand("list", "List items")
+	cmd.SetOutput(&bytes.Buffer{})
+
+	// 非终端输出默认使用 JSON
+	if f := cmd.OutputFormat(); f != "json" {
+		t.Errorf("Expected json when stdout is not a terminal, got %s", f)
+	}
+}
+
+func TestCommand_OutputFlagUserDefined(t *testing.T) {
+	var output string
+	cmd := NewCommand("build", "Build")
+	cmd.Flags.StringVar(&output, "output", "bin/app", "Output path")
+	cmd.SetOutput(&bytes.Buffer{})
+
+	if err := cmd.Run([]string{"-output", "dist/app"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if output != "dist/app" {
+		t.Errorf("Expected user flag to receive value, got %s", output)
+	}
+	if cmd.Flags.Lookup("o") != nil {
+		t.Error("Expected -o not to be registered when -output is user-defined")
+	}
+}
+
+func TestCommand_HideOutputFlag(t *testing.T) {
+	cmd := NewCommand("run", "Run")
+	cmd.HideOutputFlag = true
+	cmd.SetOutput(&bytes.Buffer{})
+
+	if err := cmd.Run(nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cmd.Flags.Lookup("output") != nil || cmd.Flags.Lookup("o") != nil {
+		t.Error("Expected -output/-o not to be registered when HideOutputFlag is set")
+	}
+}
+
+func TestProgram_OutputFlagPerInvocation(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	buf := &bytes.Buffer{}
+	prog.SetOutput(buf)
+
+	listCmd := NewCommand("list", "List items")
+	listCmd.Action = func(ctx context.Context, cmd *Command) error {
+		return cmd.Print(map[string]int{"a": 1})
+	}
+	prog.Commands = []*Command{listCmd}
+
+	if err := prog.Run([]string{"testapp", "list", "-o", "table"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "KEY   VALUE\na     1\n" {
+		t.Errorf("Expected table output, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := prog.Run([]string{"testapp", "list"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "{\n  \"a\": 1\n}\n" {
+		t.Errorf("Expected default JSON output on second run, got %q", buf.String())
+	}
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// globalFlag 由 Program 统一处理的全局标志
+//
+// 全局标志可以出现在命令名称之前或命令参数中的任意位置（"--" 之前），
+// 命令自己定义了同名标志时，该标志交给命令处理。
+type globalFlag struct {
+	name  string                               // 长名称（如 "no-input"）
+	short string                               // 短名称（可为空）
+	value string                               // 值的名称（如 "level"），为空表示布尔标志
+	count bool                                 // 可重复的计数标志（-vv 相当于 -v -v），apply 收到出现的次数
+	usage string                               // 帮助说明
+	apply func(inv *Command, val string) error // 将标志应用到本次调用
+}
+
+// globalFlags 返回当前启用的全局标志
+func (p *Program) globalFlags() []globalFlag {
+	var flags []globalFlag
+	if p.EnableLogging {
+		flags = append(flags, loggingFlags()...)
+	}
+	if p.hasInteractiveCommand() {
+		flags = append(flags, globalFlag{
+			name:  "no-input",
+			usage: "Disable interactive prompts",
+			apply: func(inv *Command, val string) error {
+				v, err := parseBoolFlag(val)
+				inv.noInput = v
+				return err
+			},
+		})
+	}
+	if p.hasDangerousCommand() {
+		flags = append(flags, yesFlag())
+	}
+	if p.EnableDryRun {
+		flags = append(flags, dryRunFlag())
+	}
+	if p.Theme != nil {
+		flags = append(flags, globalFlag{
+			name:  "color",
+			value: "when",
+			usage: "Colorize output: auto, always or never",
+			apply: func(inv *Command, val string) error {
+				mode, err := parseColorMode(val)
+				inv.colorMode = mode
+				return err
+			},
+		})
+	}
+	if p.EnablePager || p.hasPagedCommand() {
+		flags = append(flags, globalFlag{
+			name:  "no-pager",
+			usage: "Do not pipe output into a pager",
+			apply: func(inv *Command, val string) error {
+				v, err := parseBoolFlag(val)
+				inv.noPager = v
+				return err
+			},
+		})
+	}
+	return flags
+}
+
+// hasInteractiveCommand 判断是否有命令启用了交互式询问
+func (p *Program) hasInteractiveCommand() bool {
+	for _, cmd := range p.Commands {
+		if cmd.Interactive {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupGlobalFlag 按名称查找全局标志
+func lookupGlobalFlag(flags []globalFlag, name string) (globalFlag, bool) {
+	for _, f := range flags {
+		if f.name == name || (f.short != "" && f.short == name) || f.isRepeatedShort(name) {
+			return f, true
+		}
+	}
+	return globalFlag{}, false
+}
+
+// isRepeatedShort 判断 name 是否为重复的短名称（如计数标志的 -vvv）
+func (f globalFlag) isRepeatedShort(name string) bool {
+	return f.count && f.short != "" && len(name) > len(f.short) && strings.Repeat(f.short, len(name)/len(f.short)) == name
+}
+
+// splitFlagArg 将 -name、--name、-name=value 拆分为名称和值
+func splitFlagArg(arg string) (name, value string, hasValue bool) {
+	name = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+	name, value, hasValue = strings.Cut(name, "=")
+	return name, value, hasValue
+}
+
+// leadingGlobalFlags 将出现在命令名称之前的全局标志从参数中分离出来
+//
+// 返回的 lead 会在确定命令后重新加入命令参数，由 applyGlobalFlags 处理。
+func (p *Program) leadingGlobalFlags(args []string) (lead, rest []string) {
+	flags := p.globalFlags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || !isFlag(arg) {
+			return lead, args[i:]
+		}
+		name, _, hasValue := splitFlagArg(arg)
+		f, ok := lookupGlobalFlag(flags, name)
+		if !ok {
+			return lead, args[i:]
+		}
+		lead = append(lead, arg)
+		if f.value != "" && !hasValue && i+1 < len(args) {
+			i++
+			lead = append(lead, args[i])
+		}
+	}
+	return lead, nil
+}
+
+// applyGlobalFlags 从命令参数中移除全局标志并应用到本次调用，返回剩余参数
+func (p *Program) applyGlobalFlags(inv *Command, args []string) ([]string, error) {
+	flags := p.globalFlags()
+	if len(flags) == 0 {
+		return args, nil
+	}
+
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(rest, args[i:]...), nil
+		}
+		if !isFlag(arg) {
+			rest = append(rest, arg)
+			continue
+		}
+
+		name, val, hasValue := splitFlagArg(arg)
+		f, ok := lookupGlobalFlag(flags, name)
+		if !ok || inv.Flags.Lookup(name) != nil {
+			rest = append(rest, arg)
+			continue
+		}
+
+		switch {
+		case f.count && !hasValue:
+			val = "1"
+			if f.isRepeatedShort(name) {
+				val = strconv.Itoa(len(name) / len(f.short))
+			}
+		case f.value != "" && !hasValue:
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag needs an argument: -%s", name)
+			}
+			i++
+			val = args[i]
+		}
+		if err := f.apply(inv, val); err != nil {
+			return nil, fmt.Errorf("invalid value %q for flag -%s: %w", val, name, err)
+		}
+	}
+	return rest, nil
+}
+
+// flagArg 从参数中查找标志最后一次出现时的值（"--" 之后的参数不处理）
+//
+// hasArg 为 true 时，没有使用 = 的标志以下一个参数为值。
+func flagArg(args []string, name string, hasArg bool) (val string, found bool) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		n, v, hasValue := splitFlagArg(arg)
+		if !isFlag(arg) || n != name {
+			continue
+		}
+		if hasArg && !hasValue && i+1 < len(args) {
+			i++
+			v = args[i]
+		}
+		val, found = v, true
+	}
+	return val, found
+}
+
+// parseBoolFlag 解析布尔全局标志的值（未提供值时为 true）
+func parseBoolFlag(val string) (bool, error) {
+	if val == "" {
+		return true, nil
+	}
+	return strconv.ParseBool(val)
+}
+
+// appendGlobalUsage 在总体帮助中追加全局标志说明
+func (p *Program) appendGlobalUsage(b []byte, st Theme) []byte {
+	flags := p.globalFlags()
+	if len(flags) == 0 {
+		return b
+	}
+
+	names := make([]string, len(flags))
+	maxLen := 0
+	for i, f := range flags {
+		names[i] = "--" + f.name
+		if f.short != "" {
+			names[i] = "-" + f.short + ", " + names[i]
+		}
+		if f.value != "" {
+			names[i] += " " + f.value
+		}
+		maxLen = max(maxLen, len(names[i]))
+	}
+
+	b = fmt.Appendf(b, "\n%s\n", st.Heading.Render("GLOBAL OPTIONS:"))
+	for i, f := range flags {
+		b = fmt.Appendf(b, "    %s    %s\n", st.Flag.Render(fmt.Sprintf("%-*s", maxLen, names[i])), f.usage)
+	}
+	return b
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProgram_ApplyGlobalFlags(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Interactive = true
+	prog.Commands = []*Command{testCmd}
+
+	inv := prog.bind(testCmd)
+	rest, err := prog.applyGlobalFlags(inv, []string{"a", "--no-input=true", "b", "--", "--no-input"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inv.noInput {
+		t.Error("Expected --no-input to be applied")
+	}
+	if !slices.Equal(rest, []string{"a", "b", "--", "--no-input"}) {
+		t.Errorf("Unexpected remaining args: %v", rest)
+	}
+
+	inv = prog.bind(testCmd)
+	if _, err := prog.applyGlobalFlags(inv, []string{"--no-input=maybe"}); err == nil {
+		t.Error("Expected error for invalid boolean value")
+	}
+}
+
+func TestProgram_GlobalFlagShadowedByCommand(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SetOutput(&bytes.Buffer{})
+
+	var noInput bool
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Interactive = true
+	testCmd.Flags.BoolVar(&noInput, "no-input", false, "Command's own flag")
+	var global bool
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		global = cmd.noInput
+		return nil
+	}
+	prog.Commands = []*Command{testCmd}
+
+	if err := prog.Run([]string{"testapp", "test", "--no-input"}); err != nil {
+		t.Fatal(err)
+	}
+	if !noInput || global {
+		t.Errorf("Expected command flag to take precedence, got command=%v global=%v", noInput, global)
+	}
+}
+
+func TestProgram_PrintUsageGlobalOptions(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	buf := &bytes.Buffer{}
+	prog.SetOutput(buf)
+	prog.Commands = []*Command{NewCommand("test", "Test command")}
+
+	if err := prog.PrintUsage(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "GLOBAL OPTIONS:") {
+		t.Error("Expected no global options section without global flags")
+	}
+
+	prog.Commands[0].Interactive = true
+	buf.Reset()
+	if err := prog.PrintUsage(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "GLOBAL OPTIONS:\n    --no-input    Disable interactive prompts\n") {
+		t.Errorf("Expected global options section, got %q", buf.String())
+	}
+}
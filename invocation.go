@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"flag"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// flagStateInit 保护 Command.flagState 的延迟初始化
+var flagStateInit sync.Mutex
+
+// flagState 命令在所有调用之间共享的标志状态（副本之间共享）
+type flagState struct {
+	mu       sync.Mutex
+	defaults map[string]reflect.Value // 标志值的默认状态（首次复制时记录）
+	active   int                      // 正在执行的调用数量
+}
+
+// flagBinding 本次调用的标志值与原始标志值（可能通过 XxxVar 绑定到变量）的对应关系
+type flagBinding struct {
+	orig  flag.Value
+	value flag.Value
+}
+
+// sharedFlagState 返回命令的共享标志状态
+func (c *Command) sharedFlagState() *flagState {
+	flagStateInit.Lock()
+	defer flagStateInit.Unlock()
+	if c.flagState == nil {
+		c.flagState = &flagState{defaults: make(map[string]reflect.Value)}
+	}
+	return c.flagState
+}
+
+// bind 为一次调用创建命令副本
+//
+// 副本拥有独立的 FlagSet 和标志值（解析状态和标志值不会在调用之间泄漏）、输入输出流、
+// 应用名称和默认超时，原始命令不会被修改，因此同一命令的多次调用可以并发或嵌套执行。
+func (p *Program) bind(cmd *Command) *Command {
+	state := cmd.sharedFlagState()
+	inv := *cmd
+	inv.Flags, inv.boundFlags = cloneFlagSet(cmd, state)
+	inv.builtinFlags = nil
+	inv.timeoutValue = 0
+	inv.outputValue = ""
+	inv.noInput = false
+	inv.colorMode = ""
+	inv.verbosity = 0
+	inv.quiet = false
+	inv.logLevel = nil
+	inv.logFormat = ""
+	inv.logger = nil
+	inv.noPager = false
+	inv.dryRun = nil
+	inv.yes = false
+	inv.confirmedBy = ""
+	inv.theme = p.Theme
+	inv.SetStdin(p.Stdin())
+	inv.SetStdout(p.Stdout())
+	inv.SetStderr(p.Stderr())
+	inv.SetAppName(p.Name)
+	inv.processEnv = p.processEnv
+	inv.defaultTimeout = p.Timeout
+	return &inv
+}
+
+// invoke 使用本次调用独立的命令副本执行 fn
+func (p *Program) invoke(cmd *Command, fn func(inv *Command) error) error {
+	state := cmd.sharedFlagState()
+	state.enter()
+	defer state.leave()
+	return fn(p.bind(cmd))
+}
+
+// enter 记录一次调用开始
+func (s *flagState) enter() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active++
+}
+
+// leave 记录一次调用结束
+func (s *flagState) leave() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active--
+}
+
+// cloneFlagSet 复制命令的 FlagSet 定义，每个标志使用处于默认状态的独立值
+//
+// 指针类型的标志值（标准库的基础类型、自定义切片等）被复制为新值；
+// 其它类型（如 flag.Func、flag.TextVar 等内部含有指针的值）无法复制，与原始 FlagSet 共享。
+// 返回的 bindings 用于在解析后将值写回原始标志值（见 syncBoundFlags）。
+func cloneFlagSet(cmd *Command, state *flagState) (*flag.FlagSet, []flagBinding) {
+	src := cmd.Flags
+	fs := flag.NewFlagSet(src.Name(), flag.ContinueOnError)
+	fs.SetOutput(src.Output())
+	var bindings []flagBinding
+	src.VisitAll(func(f *flag.Flag) {
+		// 框架注册的内置标志绑定在原始命令的字段上，由副本重新注册
+		if cmd.isBuiltinFlag(f.Name) {
+			return
+		}
+		if c, ok := f.Value.(flagCloner); ok {
+			fs.Var(c.cloneFlag(), f.Name, f.Usage)
+			fs.Lookup(f.Name).DefValue = f.DefValue
+			return
+		}
+		value, ok := state.newValue(f)
+		if ok {
+			bindings = append(bindings, flagBinding{orig: f.Value, value: value})
+		}
+		fs.Var(value, f.Name, f.Usage)
+		fs.Lookup(f.Name).DefValue = f.DefValue
+	})
+	return fs, bindings
+}
+
+// newValue 创建处于默认状态的标志值，无法复制时返回共享的原始值和 false
+//
+// 默认状态在首次复制时记录：标准库的基础类型取自 DefValue，其它类型取自原始值；
+// 实现了 Reset() 的值在复制后调用 Reset。
+func (s *flagState) newValue(f *flag.Flag) (flag.Value, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orig := reflect.ValueOf(f.Value)
+	if orig.Kind() != reflect.Pointer || orig.IsNil() || !canCopyValue(orig.Type().Elem()) {
+		resetFlagValue(f)
+		return f.Value, false
+	}
+
+	def, ok := s.defaults[f.Name]
+	if !ok || def.Type() != orig.Type() {
+		def = reflect.New(orig.Type().Elem())
+		if isStdFlagValue(orig.Type()) {
+			_ = def.Interface().(flag.Value).Set(f.DefValue)
+		} else {
+			copyValue(def.Elem(), orig.Elem())
+		}
+		s.defaults[f.Name] = def
+	}
+
+	value := reflect.New(orig.Type().Elem())
+	copyValue(value.Elem(), def.Elem())
+	v := value.Interface().(flag.Value)
+	if r, ok := v.(flagResetter); ok {
+		r.Reset()
+	}
+	return v, true
+}
+
+// syncBoundFlags 将本次调用的标志值写回原始标志值，使通过 XxxVar 绑定的变量得到解析结果
+//
+// 绑定的变量在所有调用之间共享，为避免数据竞争，只有在没有其它调用正在执行时才写回；
+// 并发或嵌套执行时绑定的变量保持不变，需要通过 cmd.Flags 读取标志值。
+func (c *Command) syncBoundFlags() {
+	if c.flagState == nil || len(c.boundFlags) == 0 {
+		return
+	}
+	c.flagState.mu.Lock()
+	defer c.flagState.mu.Unlock()
+	if c.flagState.active > 1 {
+		return
+	}
+	for _, b := range c.boundFlags {
+		copyValue(reflect.ValueOf(b.orig).Elem(), reflect.ValueOf(b.value).Elem())
+	}
+}
+
+// isStdFlagValue 判断是否为标准库 flag 包的基础类型标志值（可以通过 Set(DefValue) 恢复默认值）
+func isStdFlagValue(t reflect.Type) bool {
+	if t.Elem().PkgPath() != "flag" {
+		return false
+	}
+	switch t.Elem().Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.String, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// canCopyValue 判断 copyValue 能否完整复制该类型的值（复制后不与原值共享可变数据）
+//
+// 不含指针的类型，以及元素不含指针的切片和映射可以复制。
+func canCopyValue(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+		return isPointerFree(t.Elem())
+	case reflect.Map:
+		return isPointerFree(t.Key()) && isPointerFree(t.Elem())
+	}
+	return isPointerFree(t)
+}
+
+// isPointerFree 判断类型是否不含指针、切片、映射、接口等引用数据
+func isPointerFree(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return isPointerFree(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if !isPointerFree(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// copyValue 将 src 复制到 dst，切片和映射复制其内容以免在调用之间共享
+func copyValue(dst, src reflect.Value) {
+	switch {
+	case src.Kind() == reflect.Slice && !src.IsNil():
+		dst.Set(reflect.AppendSlice(reflect.MakeSlice(src.Type(), 0, src.Len()), src))
+	case src.Kind() == reflect.Map && !src.IsNil():
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), iter.Value())
+		}
+		dst.Set(m)
+	default:
+		dst.Set(src)
+	}
+}
+
+// flagCloner 由能够自行创建默认状态副本的内部标志值实现（如插件命令的标志）
+type flagCloner interface {
+	cloneFlag() flag.Value
+}
+
+// flagResetter 由可以重置为默认值的自定义标志值实现
+type flagResetter interface {
+	Reset()
+}
+
+// resetFlagValue 将标志值恢复为默认值
+//
+// 标准库的基础类型通过 Set(DefValue) 恢复；实现了 Reset() 的自定义值调用 Reset；
+// 其它类型（如 flag.Func）保持不变，避免产生副作用。
+func resetFlagValue(f *flag.Flag) {
+	if r, ok := f.Value.(flagResetter); ok {
+		r.Reset()
+		return
+	}
+	g, ok := f.Value.(flag.Getter)
+	if !ok {
+		return
+	}
+	switch g.Get().(type) {
+	case bool, int, int64, uint, uint64, string, float64, time.Duration:
+		_ = f.Value.Set(f.DefValue)
+	}
+}
@@ -0,0 +1,413 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer 并发安全的 bytes.Buffer
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestProgram_FlagValuesResetBetweenRuns(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SetOutput(&bytes.Buffer{})
+
+	var name string
+	var verbose bool
+	var got []string
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Flags.StringVar(&name, "name", "world", "Name")
+	testCmd.Flags.BoolVar(&verbose, "verbose", false, "Verbose")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		got = append(got, fmt.Sprintf("%s/%v/%d", name, verbose, cmd.Flags.NFlag()))
+		return nil
+	}
+	prog.Commands = []*Command{testCmd}
+
+	if err := prog.Run([]string{"testapp", "test", "-name", "alice", "-verbose"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := prog.Run([]string{"testapp", "test"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got[0] != "alice/true/2" {
+		t.Errorf("Expected first run to see parsed values, got %s", got[0])
+	}
+	if got[1] != "world/false/0" {
+		t.Errorf("Expected second run to see defaults, got %s", got[1])
+	}
+}
+
+func TestProgram_GetDoesNotMutateCommand(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SetOutput(&bytes.Buffer{})
+	testCmd := NewCommand("test", "Test command")
+	prog.Commands = []*Command{testCmd}
+
+	cmd := prog.Get("test")
+	if cmd == testCmd {
+		t.Error("Expected Get to return a copy of the command")
+	}
+	if testCmd.appName != "" {
+		t.Errorf("Expected original command to be untouched, got appName '%s'", testCmd.appName)
+	}
+	if testCmd.stdout != nil {
+		t.Error("Expected original command output to be untouched")
+	}
+}
+
+func TestProgram_ConcurrentRuns(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.Timeout = time.Minute
+	out := &syncBuffer{}
+	prog.SetOutput(out)
+
+	countCmd := NewCommand("count", "Count command")
+	countCmd.Flags.Int("n", 0, "Count")
+	countCmd.Flags.Var(&resettableList{}, "tag", "Tag")
+	countCmd.Action = func(ctx context.Context, cmd *Command) error {
+		want := cmd.Flags.Arg(0)
+		if v := cmd.Flags.Lookup("n").Value.String(); v != want {
+			return fmt.Errorf("expected flag value %s, got %s", want, v)
+		}
+		if v := cmd.Flags.Lookup("tag").Value.String(); v != "["+want+"]" {
+			return fmt.Errorf("expected tags [%s], got %s", want, v)
+		}
+		fmt.Fprintln(cmd.Stdout(), want)
+		return nil
+	}
+
+	echoCmd := NewCommand("echo", "Echo command")
+	echoCmd.Flags.String("msg", "", "Message")
+	echoCmd.Action = func(ctx context.Context, cmd *Command) error {
+		fmt.Fprintln(cmd.Stdout(), cmd.Flags.Lookup("msg").Value.String())
+		return nil
+	}
+	prog.Commands = []*Command{countCmd, echoCmd}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 200)
+	for i := range 100 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			n := strconv.Itoa(i)
+			errs <- prog.Run([]string{"testapp", "count", "-n", n, "-tag", n, n})
+		}()
+		go func() {
+			defer wg.Done()
+			errs <- prog.Run([]string{"testapp", "echo", "-msg", "hi"})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestProgram_ParallelRunsOfOneCommand(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SetOutput(&syncBuffer{})
+
+	const n = 4
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	release := make(chan struct{})
+	slowCmd := NewCommand("slow", "Slow command")
+	slowCmd.Action = func(ctx context.Context, cmd *Command) error {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		if running == n {
+			close(release)
+		}
+		mu.Unlock()
+
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}
+	prog.Commands = []*Command{slowCmd}
+
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			if err := prog.Run([]string{"testapp", "slow"}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if maxRunning != n {
+		t.Errorf("Expected %d invocations to run in parallel, got at most %d", n, maxRunning)
+	}
+}
+
+func TestProgram_ReentrantRun(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	out := &bytes.Buffer{}
+	prog.SetOutput(out)
+
+	var depth int
+	nestCmd := NewCommand("nest", "Nested command")
+	nestCmd.Flags.IntVar(&depth, "depth", 0, "Depth")
+	nestCmd.Action = func(ctx context.Context, cmd *Command) error {
+		d, _ := strconv.Atoi(cmd.Flags.Lookup("depth").Value.String())
+		if d < 2 {
+			if err := prog.RunContext(ctx, []string{"testapp", "nest", "-depth", strconv.Itoa(d + 1)}); err != nil {
+				return err
+			}
+		}
+		// 嵌套调用不会影响本次调用的标志值
+		fmt.Fprintf(cmd.Stdout(), "%d=%s ", d, cmd.Flags.Lookup("depth").Value.String())
+		return nil
+	}
+	prog.Commands = []*Command{nestCmd}
+
+	done := make(chan error, 1)
+	go func() { done <- prog.Run([]string{"testapp", "nest"}) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Re-entrant run deadlocked")
+	}
+
+	if out.String() != "2=2 1=1 0=0 " {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+	// 嵌套调用不会写回绑定的变量，其中保留外层调用的值
+	if depth != 0 {
+		t.Errorf("Expected bound variable to keep the outer value, got %d", depth)
+	}
+}
+
+func TestProgram_ConcurrentRunsDoNotWriteBoundFlags(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SetOutput(&syncBuffer{})
+
+	var name string
+	started := make(chan struct{})
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Flags.StringVar(&name, "name", "", "Name")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		if cmd.Flags.Lookup("name").Value.String() != "first" {
+			return nil
+		}
+		close(started)
+		// 另一次调用解析标志期间持续读取绑定的变量，写回会产生数据竞争（go test -race）
+		for deadline := time.Now().Add(50 * time.Millisecond); time.Now().Before(deadline); {
+			if name != "first" {
+				return fmt.Errorf("bound variable changed to %q", name)
+			}
+		}
+		return nil
+	}
+	prog.Commands = []*Command{testCmd}
+
+	done := make(chan error, 1)
+	go func() { done <- prog.Run([]string{"testapp", "test", "-name", "first"}) }()
+	<-started
+	if err := prog.Run([]string{"testapp", "test", "-name", "second"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有其它调用时仍然写回
+	if err := prog.Run([]string{"testapp", "test", "-name", "solo"}); err != nil {
+		t.Fatal(err)
+	}
+	if name != "solo" {
+		t.Errorf("Expected bound variable to be updated, got %q", name)
+	}
+}
+
+func TestProgram_TextFlagShared(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SetOutput(&bytes.Buffer{})
+
+	var ip net.IP
+	var got []string
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Flags.TextVar(&ip, "ip", net.IPv4(127, 0, 0, 1), "Address")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		got = append(got, cmd.Flags.Lookup("ip").Value.String()+"/"+ip.String())
+		return nil
+	}
+	prog.Commands = []*Command{testCmd}
+
+	if err := prog.Run([]string{"testapp", "test", "-ip", "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	// TextVar 的值内部含有指针，无法复制，与原始 FlagSet 共享
+	if fs := testCmd.Flags.Lookup("ip"); fs.Value.String() != "10.0.0.1" {
+		t.Errorf("Expected text flag to be shared, got %q", fs.Value.String())
+	}
+	if fmt.Sprint(got) != "[10.0.0.1/10.0.0.1]" {
+		t.Errorf("Unexpected values: %v", got)
+	}
+}
+
+func TestCanCopyValue(t *testing.T) {
+	type point struct{ X, Y int }
+	type named struct{ Names []string }
+	tests := []struct {
+		value any
+		want  bool
+	}{
+		{"", true},
+		{point{}, true},
+		{[]string{}, true},
+		{map[string]int{}, true},
+		{[][]string{}, false},
+		{named{}, false},
+		{(*int)(nil), false},
+		{(any)(nil), false},
+	}
+	for _, tt := range tests {
+		typ := reflect.TypeOf(&tt.value).Elem()
+		if tt.value != nil {
+			typ = reflect.TypeOf(tt.value)
+		}
+		if got := canCopyValue(typ); got != tt.want {
+			t.Errorf("canCopyValue(%s) = %v, want %v", typ, got, tt.want)
+		}
+	}
+}
+
+func TestProgram_FlagValuesIsolated(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SetOutput(&bytes.Buffer{})
+
+	// 没有实现 Reset 的自定义切片和 flag.Func
+	var tags plainList
+	var funcCalls []string
+	var got []string
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Flags.Var(&tags, "tag", "Tag")
+	testCmd.Flags.Func("fn", "Func", func(s string) error {
+		funcCalls = append(funcCalls, s)
+		return nil
+	})
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		got = append(got, cmd.Flags.Lookup("tag").Value.String()+"/"+fmt.Sprint([]string(tags)))
+		return nil
+	}
+	prog.Commands = []*Command{testCmd}
+
+	if err := prog.Run([]string{"testapp", "test", "-tag", "a", "-tag", "b", "-fn", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := prog.Run([]string{"testapp", "test", "-tag", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := prog.Run([]string{"testapp", "test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"[a b]/[a b]", "[c]/[c]", "[]/[]"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if fmt.Sprint(funcCalls) != "[x]" {
+		t.Errorf("Expected func flag to be called once, got %v", funcCalls)
+	}
+}
+
+func TestProgram_ConcurrentHelp(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SetOutput(&syncBuffer{})
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Flags.String("name", "", "Name")
+	prog.Commands = []*Command{testCmd}
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = prog.Run([]string{"testapp", "help", "test"})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = prog.Run([]string{"testapp", "test", "-name", "x"})
+		}()
+	}
+	wg.Wait()
+}
+
+// resettableList 实现 flagResetter 的可重复标志
+type resettableList []string
+
+func (l *resettableList) String() string     { return fmt.Sprint([]string(*l)) }
+func (l *resettableList) Set(s string) error { *l = append(*l, s); return nil }
+func (l *resettableList) Reset()             { *l = nil }
+
+// plainList 没有实现 Reset 的可重复标志
+type plainList []string
+
+func (l *plainList) String() string     { return fmt.Sprint([]string(*l)) }
+func (l *plainList) Set(s string) error { *l = append(*l, s); return nil }
+
+func TestResetFlagValue(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var list resettableList
+	fs.Var(&list, "tag", "Tag")
+	d := fs.Duration("wait", time.Second, "Wait")
+	calls := 0
+	fs.Func("fn", "Func", func(string) error { calls++; return nil })
+
+	if err := fs.Parse([]string{"-tag", "a", "-wait", "5s", "-fn", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	fs.VisitAll(resetFlagValue)
+
+	if len(list) != 0 {
+		t.Errorf("Expected list to be reset, got %v", list)
+	}
+	if *d != time.Second {
+		t.Errorf("Expected duration to be reset to 1s, got %s", *d)
+	}
+	if calls != 1 {
+		t.Errorf("Expected func flag not to be invoked by reset, got %d calls", calls)
+	}
+}
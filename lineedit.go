@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// errInterrupted 行编辑时按下了 Ctrl+C
+var errInterrupted = errors.New("interrupted")
+
+// 行编辑器识别的按键
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyBackspace = 8
+	keyTab       = 9
+	keyLF        = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// completeFunc 根据光标前的文本返回补全候选（完整的单词）
+type completeFunc func(before string) []string
+
+// lineEditor 简单的终端行编辑器
+//
+// 输入需要处于逐字符模式（见 makeRaw）。支持左右移动、Home/End、删除、
+// Ctrl+U/K/W、上下键浏览历史和 Tab 补全。
+type lineEditor struct {
+	in       io.Reader
+	out      io.Writer
+	history  []string
+	complete completeFunc
+
+	buf       []rune // 当前行
+	pos       int    // 光标位置
+	histIndex int    // 正在浏览的历史记录（len(history) 表示当前行）
+	draft     []rune // 开始浏览历史前的当前行
+	lastTab   bool   // 上一个按键是否为 Tab（连续两次 Tab 列出候选）
+}
+
+// readLine 读取一行，Ctrl+C 返回 errInterrupted，空行上的 Ctrl+D 返回 io.EOF
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	e.buf = e.buf[:0]
+	e.pos = 0
+	e.histIndex = len(e.history)
+	e.lastTab = false
+	e.write(prompt)
+
+	for {
+		b, err := e.readByte()
+		if err != nil {
+			if err == io.EOF && len(e.buf) > 0 {
+				e.write("\n")
+				return string(e.buf), nil
+			}
+			return "", err
+		}
+
+		tab := false
+		switch b {
+		case keyEnter, keyLF:
+			e.write("\n")
+			return string(e.buf), nil
+		case keyCtrlC:
+			e.write("^C\n")
+			return "", errInterrupted
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				e.write("\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+		case keyCtrlA:
+			e.pos = 0
+		case keyCtrlE:
+			e.pos = len(e.buf)
+		case keyCtrlB:
+			e.pos = max(e.pos-1, 0)
+		case keyCtrlF:
+			e.pos = min(e.pos+1, len(e.buf))
+		case keyBackspace, keyDelete:
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = slices.Delete(e.buf, 0, e.pos)
+			e.pos = 0
+		case keyCtrlW:
+			start := e.pos
+			for start > 0 && e.buf[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && e.buf[start-1] != ' ' {
+				start--
+			}
+			e.buf = slices.Delete(e.buf, start, e.pos)
+			e.pos = start
+		case keyCtrlP:
+			e.historyMove(-1)
+		case keyCtrlN:
+			e.historyMove(1)
+		case keyCtrlL:
+			e.write("\x1b[H\x1b[2J")
+		case keyTab:
+			tab = true
+			e.completeWord(prompt)
+		case keyEscape:
+			if err := e.readEscape(); err != nil {
+				return "", err
+			}
+		default:
+			if b >= 0x20 {
+				r, err := e.readRune(b)
+				if err != nil {
+					return "", err
+				}
+				e.buf = slices.Insert(e.buf, e.pos, r)
+				e.pos++
+			}
+		}
+		e.lastTab = tab
+		e.refresh(prompt)
+	}
+}
+
+// readEscape 处理方向键等转义序列
+func (e *lineEditor) readEscape() error {
+	b, err := e.readByte()
+	if err != nil {
+		return err
+	}
+	if b != '[' && b != 'O' {
+		return nil
+	}
+	b, err = e.readByte()
+	if err != nil {
+		return err
+	}
+	switch b {
+	case 'A':
+		e.historyMove(-1)
+	case 'B':
+		e.historyMove(1)
+	case 'C':
+		e.pos = min(e.pos+1, len(e.buf))
+	case 'D':
+		e.pos = max(e.pos-1, 0)
+	case 'H':
+		e.pos = 0
+	case 'F':
+		e.pos = len(e.buf)
+	case '1', '3', '4', '7', '8':
+		// ESC [ n ~ 形式：1/7 Home，4/8 End，3 Delete
+		if t, err := e.readByte(); err != nil {
+			return err
+		} else if t != '~' {
+			return nil
+		}
+		switch b {
+		case '1', '7':
+			e.pos = 0
+		case '4', '8':
+			e.pos = len(e.buf)
+		case '3':
+			e.deleteAt(e.pos)
+		}
+	}
+	return nil
+}
+
+// historyMove 在历史记录中向前（-1）或向后（1）移动
+func (e *lineEditor) historyMove(delta int) {
+	next := e.histIndex + delta
+	if next < 0 || next > len(e.history) {
+		return
+	}
+	if e.histIndex == len(e.history) {
+		e.draft = slices.Clone(e.buf)
+	}
+	e.histIndex = next
+	if next == len(e.history) {
+		e.buf = slices.Clone(e.draft)
+	} else {
+		e.buf = []rune(e.history[next])
+	}
+	e.pos = len(e.buf)
+}
+
+// completeWord 补全光标所在的单词
+//
+// 唯一候选时直接补全并追加空格；多个候选时补全公共前缀，连续两次 Tab 列出所有候选。
+func (e *lineEditor) completeWord(prompt string) {
+	if e.complete == nil {
+		return
+	}
+	before := string(e.buf[:e.pos])
+	candidates := e.complete(before)
+	if len(candidates) == 0 {
+		return
+	}
+
+	start := strings.LastIndexAny(before, " \t") + 1
+	word := before[start:]
+	insert := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		insert += " "
+	}
+	if strings.HasPrefix(insert, word) && len(insert) > len(word) {
+		add := []rune(insert[len(word):])
+		e.buf = slices.Insert(e.buf, e.pos, add...)
+		e.pos += len(add)
+		return
+	}
+
+	if len(candidates) > 1 && e.lastTab {
+		e.write("\n" + strings.Join(candidates, "  ") + "\n")
+	}
+}
+
+// refresh 重绘当前行并将光标移动到正确位置
+func (e *lineEditor) refresh(prompt string) {
+	var b []byte
+	b = append(b, '\r')
+	b = append(b, prompt...)
+	b = append(b, string(e.buf)...)
+	b = append(b, "\x1b[K"...)
+	if back := displayWidth(string(e.buf[e.pos:])); back > 0 {
+		b = fmt.Appendf(b, "\x1b[%dD", back)
+	}
+	_, _ = e.out.Write(b)
+}
+
+// deleteAt 删除指定位置的字符
+func (e *lineEditor) deleteAt(i int) {
+	if i < len(e.buf) {
+		e.buf = slices.Delete(e.buf, i, i+1)
+	}
+}
+
+// write 输出文本（忽略写入错误）
+func (e *lineEditor) write(s string) {
+	_, _ = io.WriteString(e.out, s)
+}
+
+// readByte 读取一个字节
+func (e *lineEditor) readByte() (byte, error) {
+	var b [1]byte
+	for {
+		n, err := e.in.Read(b[:])
+		if n == 1 {
+			return b[0], nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// readRune 以 first 为首字节读取一个完整的 UTF-8 字符
+func (e *lineEditor) readRune(first byte) (rune, error) {
+	n := 1
+	switch {
+	case first >= 0xF0:
+		n = 4
+	case first >= 0xE0:
+		n = 3
+	case first >= 0xC0:
+		n = 2
+	}
+	p := []byte{first}
+	for len(p) < n {
+		b, err := e.readByte()
+		if err != nil {
+			return 0, err
+		}
+		p = append(p, b)
+	}
+	return []rune(string(p))[0], nil
+}
+
+// commonPrefix 返回所有字符串的公共前缀
+func commonPrefix(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	prefix := items[0]
+	for _, s := range items[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
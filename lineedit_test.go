@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(input string, history ...string) *lineEditor {
+	return &lineEditor{
+		in:      strings.NewReader(input),
+		out:     &bytes.Buffer{},
+		history: history,
+	}
+}
+
+func TestLineEditor_Editing(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "hello\r", "hello"},
+		{"backspace", "helo\x7f\x7fllo\r", "hello"},
+		{"left arrow insert", "hllo\x1b[D\x1b[D\x1b[De\r", "hello"},
+		{"home and end", "ello\x01h\x05!\r", "hello!"},
+		{"delete key", "hxello\x01\x1b[C\x1b[3~\r", "hello"},
+		{"kill to end", "hello world\x01\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x0b\r", "hello"},
+		{"kill to start", "junk hello\x01\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x15\r", "hello"},
+		{"delete word", "hello world\x17\r", "hello "},
+		{"utf8", "你好\r", "你好"},
+	}
+
+	for _, tt := range tests {
+		e := newTestEditor(tt.input)
+		got, err := e.readLine("> ")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLineEditor_History(t *testing.T) {
+	e := newTestEditor("\x1b[A\x1b[A\r", "first", "second")
+	got, err := e.readLine("> ")
+	if err != nil || got != "first" {
+		t.Errorf("Expected 'first', got %q (%v)", got, err)
+	}
+
+	e = newTestEditor("draft\x1b[A\x1b[B\r", "first")
+	got, err = e.readLine("> ")
+	if err != nil || got != "draft" {
+		t.Errorf("Expected draft to be restored, got %q (%v)", got, err)
+	}
+}
+
+func TestLineEditor_ControlKeys(t *testing.T) {
+	e := newTestEditor("abc\x03")
+	if _, err := e.readLine("> "); !errors.Is(err, errInterrupted) {
+		t.Errorf("Expected errInterrupted for Ctrl+C, got %v", err)
+	}
+
+	e = newTestEditor("\x04")
+	if _, err := e.readLine("> "); err != io.EOF {
+		t.Errorf("Expected io.EOF for Ctrl+D on empty line, got %v", err)
+	}
+}
+
+func TestLineEditor_Completion(t *testing.T) {
+	complete := func(before string) []string {
+		var out []string
+		for _, c := range []string{"deploy", "describe", "status"} {
+			if strings.HasPrefix(c, before) {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+
+	e := newTestEditor("st\t\r")
+	e.complete = complete
+	got, _ := e.readLine("> ")
+	if got != "status " {
+		t.Errorf("Expected unique completion, got %q", got)
+	}
+
+	out := &bytes.Buffer{}
+	e = newTestEditor("d\t\t\r")
+	e.out = out
+	e.complete = complete
+	got, _ = e.readLine("> ")
+	if got != "de" {
+		t.Errorf("Expected common prefix completion, got %q", got)
+	}
+	if !strings.Contains(out.String(), "deploy  describe") {
+		t.Errorf("Expected candidates to be listed on double tab, got %q", out.String())
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	if got := commonPrefix([]string{"deploy", "describe"}); got != "de" {
+		t.Errorf("Expected 'de', got %q", got)
+	}
+	if got := commonPrefix([]string{"a"}); got != "a" {
+		t.Errorf("Expected 'a', got %q", got)
+	}
+	if got := commonPrefix(nil); got != "" {
+		t.Errorf("Expected empty prefix, got %q", got)
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+)
+
+// DefaultLogLevel 未指定 -v、-q 或 --log-level 时的日志级别
+const DefaultLogLevel = slog.LevelWarn
+
+// 日志格式（--log-format 标志的值）
+const (
+	LogFormatText = "text" // key=value 文本格式（默认，不含时间）
+	LogFormatJSON = "json" // 每行一个 JSON 对象
+)
+
+// loggerKey context 中存放日志记录器的键
+type loggerKey struct{}
+
+// LoggerFrom 返回 context 中的日志记录器，没有时返回 slog.Default()
+//
+// 启用 Program.EnableLogging 时，传给 Action 的 context 中包含按日志标志配置的记录器。
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// Logger 返回本次调用的日志记录器
+//
+// 启用 Program.EnableLogging 时按日志标志配置；否则返回以 DefaultLogLevel 写入标准错误的文本记录器。
+func (c *Command) Logger() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return newLogger(c.Stderr(), DefaultLogLevel, LogFormatText)
+}
+
+// loggingFlags 返回日志相关的全局标志
+func loggingFlags() []globalFlag {
+	return []globalFlag{
+		{
+			name:  "verbose",
+			short: "v",
+			count: true,
+			usage: "Increase log verbosity (repeatable: -vv)",
+			apply: func(inv *Command, val string) error {
+				n, err := strconv.Atoi(val)
+				inv.verbosity += n
+				return err
+			},
+		},
+		{
+			name:  "quiet",
+			short: "q",
+			usage: "Only log errors",
+			apply: func(inv *Command, val string) error {
+				v, err := parseBoolFlag(val)
+				inv.quiet = v
+				return err
+			},
+		},
+		{
+			name:  "log-level",
+			value: "level",
+			usage: "Log level: debug, info, warn or error",
+			apply: func(inv *Command, val string) error {
+				var level slog.Level
+				if err := level.UnmarshalText([]byte(val)); err != nil {
+					return err
+				}
+				inv.logLevel = &level
+				return nil
+			},
+		},
+		{
+			name:  "log-format",
+			value: "format",
+			usage: "Log format: text or json",
+			apply: func(inv *Command, val string) error {
+				if val != LogFormatText && val != LogFormatJSON {
+					return fmt.Errorf("must be %s or %s", LogFormatText, LogFormatJSON)
+				}
+				inv.logFormat = val
+				return nil
+			},
+		},
+	}
+}
+
+// setupLogger 按日志标志创建本次调用的日志记录器
+//
+// --log-level 优先；否则 -q 为 error，每个 -v 将级别从 DefaultLogLevel 降低一级（-v 为 info，-vv 为 debug）。
+func (c *Command) setupLogger() *slog.Logger {
+	level := DefaultLogLevel
+	switch {
+	case c.logLevel != nil:
+		level = *c.logLevel
+	case c.quiet:
+		level = slog.LevelError
+	default:
+		level -= slog.Level(4 * c.verbosity)
+	}
+	format := c.logFormat
+	if format == "" {
+		format = LogFormatText
+	}
+	c.logger = newLogger(c.Stderr(), level, format)
+	return c.logger
+}
+
+// newLogger 创建写入 w 的日志记录器，文本格式不输出时间
+func newLogger(w io.Writer, level slog.Level, format string) *slog.Logger {
+	if format == LogFormatJSON {
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+	}
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
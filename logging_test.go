@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// newLoggingProgram 创建启用日志的程序，log 命令在每个级别各输出一条日志
+func newLoggingProgram() (*Program, *bytes.Buffer, *bytes.Buffer) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.EnableLogging = true
+
+	cmd := NewCommand("log", "Log at every level")
+	cmd.Action = func(ctx context.Context, cmd *Command) error {
+		if LoggerFrom(ctx) != cmd.Logger() {
+			return context.Canceled
+		}
+		logger := cmd.Logger()
+		logger.Debug("debug message")
+		logger.Info("info message", "user", "ada")
+		logger.Warn("warn message")
+		logger.Error("error message")
+		_, err := cmd.Stdout().Write([]byte("output\n"))
+		return err
+	}
+	prog.Commands = []*Command{cmd}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	prog.SetStdout(stdout)
+	prog.SetStderr(stderr)
+	return prog, stdout, stderr
+}
+
+func TestLogging_Levels(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{nil, []string{"warn", "error"}},
+		{[]string{"-v"}, []string{"info", "warn", "error"}},
+		{[]string{"-vv"}, []string{"debug", "info", "warn", "error"}},
+		{[]string{"-v", "--verbose"}, []string{"debug", "info", "warn", "error"}},
+		{[]string{"-q"}, []string{"error"}},
+		{[]string{"--log-level", "info"}, []string{"info", "warn", "error"}},
+		{[]string{"-vv", "--log-level=error"}, []string{"error"}},
+	}
+	for _, tt := range tests {
+		prog, stdout, stderr := newLoggingProgram()
+		args := append([]string{"testapp", "log"}, tt.args...)
+		if err := prog.Run(args); err != nil {
+			t.Fatalf("%v: expected no error, got %v", tt.args, err)
+		}
+		if stdout.String() != "output\n" {
+			t.Errorf("%v: expected logs to stay out of stdout, got %q", tt.args, stdout.String())
+		}
+
+		var got []string
+		for _, line := range strings.Split(strings.TrimSpace(stderr.String()), "\n") {
+			if _, msg, ok := strings.Cut(line, "msg=\""); ok {
+				got = append(got, strings.Fields(msg)[0])
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%v: expected levels %v, got %v\n%s", tt.args, tt.want, got, stderr.String())
+		}
+	}
+}
+
+func TestLogging_TextFormat(t *testing.T) {
+	prog, _, stderr := newLoggingProgram()
+	if err := prog.Run([]string{"testapp", "-v", "log"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stderr.String(), "level=INFO msg=\"info message\" user=ada\n") {
+		t.Errorf("Expected text log without time, got %q", stderr.String())
+	}
+	if strings.Contains(stderr.String(), "time=") {
+		t.Errorf("Expected text logs to omit the time, got %q", stderr.String())
+	}
+}
+
+func TestLogging_JSONFormat(t *testing.T) {
+	prog, _, stderr := newLoggingProgram()
+	if err := prog.Run([]string{"testapp", "log", "--log-format", "json", "-q"}); err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(stderr.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected a single JSON log line, got %q (%v)", stderr.String(), err)
+	}
+	if entry["level"] != "ERROR" || entry["msg"] != "error message" || entry["time"] == nil {
+		t.Errorf("Unexpected JSON log entry: %v", entry)
+	}
+}
+
+func TestLogging_InvalidFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"testapp", "log", "--log-level", "loud"},
+		{"testapp", "log", "--log-format=xml"},
+	} {
+		prog, _, _ := newLoggingProgram()
+		if err := prog.Run(args); err == nil || !strings.Contains(err.Error(), "invalid value") {
+			t.Errorf("%v: expected invalid value error, got %v", args[2:], err)
+		}
+	}
+}
+
+func TestLogging_VersionFlag(t *testing.T) {
+	prog, stdout, _ := newLoggingProgram()
+	if err := prog.Run([]string{"testapp", "log", "--version"}); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "testapp version 1.0.0\n" {
+		t.Errorf("Expected --version to print the version, got %q", stdout.String())
+	}
+
+	// 启用日志后 -v 表示提高详细程度
+	stdout.Reset()
+	if err := prog.Run([]string{"testapp", "log", "-v"}); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "output\n" {
+		t.Errorf("Expected -v to run the command, got %q", stdout.String())
+	}
+}
+
+func TestLogging_CommandFlagWins(t *testing.T) {
+	prog, _, stderr := newLoggingProgram()
+	var quiet bool
+	prog.Commands[0].Flags.BoolVar(&quiet, "q", false, "Command's own -q")
+	if err := prog.Run([]string{"testapp", "log", "-q"}); err != nil {
+		t.Fatal(err)
+	}
+	if !quiet || !strings.Contains(stderr.String(), "warn message") {
+		t.Errorf("Expected -q to be handled by the command, quiet=%v logs=%q", quiet, stderr.String())
+	}
+}
+
+func TestLogging_Disabled(t *testing.T) {
+	cmd := NewCommand("test", "Test")
+	stderr := &bytes.Buffer{}
+	cmd.SetStderr(stderr)
+	cmd.Logger().Info("hidden")
+	cmd.Logger().Warn("shown")
+	if strings.Contains(stderr.String(), "hidden") || !strings.Contains(stderr.String(), "shown") {
+		t.Errorf("Expected default logger at warn level, got %q", stderr.String())
+	}
+	if LoggerFrom(context.Background()) != slog.Default() {
+		t.Error("Expected slog.Default() without a logger in the context")
+	}
+}
+
+func TestLogging_Usage(t *testing.T) {
+	prog, stdout, _ := newLoggingProgram()
+	if err := prog.PrintUsage(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"-v, --verbose", "-q, --quiet", "--log-level level", "--log-format format"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Expected %q in global options, got:\n%s", want, stdout.String())
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultPager 未设置 <PREFIX>_PAGER 和 PAGER 时使用的分页器
+//
+// -F 内容不足一屏时直接输出并退出，-R 保留颜色，-X 退出后不清屏。
+const DefaultPager = "less -FRX"
+
+// pagerWriter 写入分页器的标准输入
+//
+// 分页器的输出最终显示在终端上，因此对框架而言它被视为终端（表格和颜色按终端处理）。
+type pagerWriter struct {
+	io.Writer
+}
+
+// IsTerminal 实现 Terminal 接口
+func (pagerWriter) IsTerminal() bool { return true }
+
+// pagerCommand 返回分页器命令行，禁用分页器时返回空字符串
+//
+// <PREFIX>_PAGER 优先于 PAGER，值为空或 cat 表示不使用分页器；
+// 设置了 <PREFIX>_NO_PAGER 时同样不使用分页器。
+func (p *Program) pagerCommand() string {
+	prefix := p.EnvPrefix()
+	if p.Getenv(prefix+"_NO_PAGER") != "" {
+		return ""
+	}
+	line, ok := p.LookupEnv(prefix + "_PAGER")
+	if !ok {
+		if line = p.Getenv("PAGER"); line == "" {
+			line = DefaultPager
+		}
+	}
+	if line = strings.TrimSpace(line); line == "cat" {
+		return ""
+	}
+	return line
+}
+
+// withPager 标准输出为终端时，将 fn 的输出通过分页器显示，否则直接写入标准输出
+//
+// 分页器找不到时直接输出。用户提前退出分页器后，写入返回的 broken pipe 错误会被忽略，
+// 分页器本身的退出状态也不影响返回值。
+func (p *Program) withPager(fn func(w io.Writer) error) error {
+	stdout := p.Stdout()
+	if !isTerminal(stdout) {
+		return fn(stdout)
+	}
+	c := p.pagerProcess()
+	if c == nil {
+		return fn(stdout)
+	}
+	c.Stdout = stdout
+	c.Stderr = p.Stderr()
+	in, err := c.StdinPipe()
+	if err != nil {
+		return fn(stdout)
+	}
+	if err := c.Start(); err != nil {
+		return fn(stdout)
+	}
+	// 即使 fn 发生 panic 也要等待分页器退出，以便恢复终端状态
+	defer func() {
+		_ = in.Close()
+		_ = c.Wait()
+	}()
+
+	err = fn(pagerWriter{in})
+	if isBrokenPipe(err) {
+		return nil
+	}
+	return err
+}
+
+// pagerProcess 创建分页器进程，未启用或找不到分页器时返回 nil
+//
+// 分页器命令行按 shell 规则拆分，程序名在程序的 PATH 中查找。
+func (p *Program) pagerProcess() *exec.Cmd {
+	line := p.pagerCommand()
+	if line == "" {
+		return nil
+	}
+	args, err := splitArgs(line)
+	if err != nil || len(args) == 0 {
+		return nil
+	}
+	path := p.lookPath(args[0])
+	if path == "" {
+		return nil
+	}
+	c := exec.Command(path, args[1:]...)
+	c.Env = p.Environ()
+	c.Dir = p.dir
+	return c
+}
+
+// lookPath 在程序的 PATH 中查找可执行文件，name 包含路径分隔符时相对于工作目录解析
+func (p *Program) lookPath(name string) string {
+	if strings.ContainsAny(name, `/\`) {
+		path, err := exec.LookPath(p.Path(name))
+		if err != nil {
+			return ""
+		}
+		return path
+	}
+	for _, dir := range p.pathDirs() {
+		if path, err := exec.LookPath(filepath.Join(dir, name)); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// pathDirs 返回程序 PATH 中的绝对路径目录
+//
+// 与 exec.LookPath 一致，空目录和 "." 等相对路径会被跳过，避免执行当前目录中的同名文件。
+func (p *Program) pathDirs() []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(p.Getenv("PATH")) {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// pageHelp 启用 EnablePager 且参数中没有 --no-pager 时，将帮助通过分页器显示
+func (p *Program) pageHelp(args []string, fn func(w io.Writer) error) error {
+	if !p.EnablePager || noPagerArg(args) {
+		return fn(p.Stdout())
+	}
+	return p.withPager(fn)
+}
+
+// hasPagedCommand 判断是否有命令启用了分页器
+func (p *Program) hasPagedCommand() bool {
+	for _, cmd := range p.Commands {
+		if cmd.Pager {
+			return true
+		}
+	}
+	return false
+}
+
+// noPagerArg 判断参数中是否指定了 --no-pager（"--" 之后的参数不处理）
+//
+// 用于在确定命令之前输出的帮助。
+func noPagerArg(args []string) bool {
+	val, ok := flagArg(args, "no-pager", false)
+	if !ok {
+		return false
+	}
+	v, err := parseBoolFlag(val)
+	return err == nil && v
+}
@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// newPagerProgram 创建使用 sh 脚本作为分页器的程序，返回模拟终端的标准输出
+func newPagerProgram(t *testing.T, pager string) (*Program, *bytes.Buffer) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("pager scripts use sh")
+	}
+	path := filepath.Join(t.TempDir(), "pager")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+pager+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	prog := NewProgram("testapp", "1.0.0")
+	prog.EnablePager = true
+	prog.SetEnviron([]string{"PATH=" + os.Getenv("PATH"), "PAGER=" + path})
+	out := &bytes.Buffer{}
+	prog.SetStdout(ttyWriter{out})
+	prog.SetStderr(&bytes.Buffer{})
+
+	logCmd := NewCommand("log", "Show the log")
+	logCmd.Pager = true
+	logCmd.Action = func(ctx context.Context, cmd *Command) error {
+		for i := range 100000 {
+			if _, err := fmt.Fprintln(cmd.Stdout(), "line", i); err != nil {
+				return fmt.Errorf("write log: %w", err)
+			}
+		}
+		return nil
+	}
+	prog.Commands = []*Command{logCmd, NewCommand("noop", "Do nothing")}
+	return prog, out
+}
+
+func TestProgram_PagerCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		env  []string
+		want string
+	}{
+		{"default", nil, DefaultPager},
+		{"PAGER", []string{"PAGER=more"}, "more"},
+		{"prefix wins", []string{"PAGER=more", "TESTAPP_PAGER=most -s"}, "most -s"},
+		{"empty prefix disables", []string{"PAGER=more", "TESTAPP_PAGER="}, ""},
+		{"cat disables", []string{"PAGER=cat"}, ""},
+		{"no pager", []string{"TESTAPP_NO_PAGER=1"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog := NewProgram("testapp", "1.0.0")
+			prog.SetEnviron(tt.env)
+			if got := prog.pagerCommand(); got != tt.want {
+				t.Errorf("Expected pager %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProgram_PagerHelp(t *testing.T) {
+	prog, out := newPagerProgram(t, `sed 's/^/| /'`)
+
+	if err := prog.Run([]string{"testapp", "help"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "| testapp version 1.0.0\n") || !strings.Contains(out.String(), "| GLOBAL OPTIONS:\n") {
+		t.Errorf("Expected help through the pager, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := prog.Run([]string{"testapp", "help", "log"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "| Usage: testapp log") {
+		t.Errorf("Expected command help through the pager, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := prog.PrintUsage(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "| testapp version") {
+		t.Errorf("Expected PrintUsage through the pager, got:\n%s", out.String())
+	}
+}
+
+func TestProgram_PagerDisabled(t *testing.T) {
+	prog, out := newPagerProgram(t, `sed 's/^/| /'`)
+
+	if err := prog.Run([]string{"testapp", "--no-pager", "help"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "testapp version 1.0.0\n") {
+		t.Errorf("Expected help without the pager, got:\n%s", out.String())
+	}
+
+	// 标准输出不是终端
+	buf := &bytes.Buffer{}
+	prog.SetStdout(buf)
+	if err := prog.Run([]string{"testapp", "help"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "testapp version 1.0.0\n") {
+		t.Errorf("Expected help without the pager, got:\n%s", buf.String())
+	}
+
+	// 找不到分页器时直接输出
+	prog.SetStdout(ttyWriter{out})
+	prog.SetEnviron([]string{"PATH=" + t.TempDir(), "TESTAPP_PAGER=missing-pager -x"})
+	out.Reset()
+	if err := prog.Run([]string{"testapp", "help"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "testapp version 1.0.0\n") {
+		t.Errorf("Expected help without the pager, got:\n%s", out.String())
+	}
+}
+
+func TestProgram_PagerCommandOutput(t *testing.T) {
+	prog, out := newPagerProgram(t, `sed 's/^/| /'`)
+
+	if err := prog.Run([]string{"testapp", "log"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "| line 0\n| line 1\n") || !strings.HasSuffix(out.String(), "| line 99999\n") {
+		t.Errorf("Expected command output through the pager, got %d bytes", out.Len())
+	}
+
+	out.Reset()
+	if err := prog.Run([]string{"testapp", "log", "--no-pager"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "line 0\n") {
+		t.Errorf("Expected command output without the pager, got %q...", out.String()[:min(out.Len(), 20)])
+	}
+}
+
+func TestProgram_PagerExitsEarly(t *testing.T) {
+	// 分页器读取第一行后退出，之后的写入返回 broken pipe
+	prog, out := newPagerProgram(t, `head -n 1`)
+
+	if err := prog.Run([]string{"testapp", "log"}); err != nil {
+		t.Fatalf("Expected broken pipe to be ignored, got: %v", err)
+	}
+	if out.String() != "line 0\n" {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+}
+
+func TestProgram_PagerGlobalFlag(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	out := &bytes.Buffer{}
+	prog.SetOutput(out)
+	prog.Commands = []*Command{NewCommand("noop", "Do nothing")}
+
+	if err := prog.PrintUsage(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "--no-pager") {
+		t.Errorf("Expected no --no-pager flag without a pager, got:\n%s", out.String())
+	}
+
+	prog.Commands[0].Pager = true
+	out.Reset()
+	if err := prog.PrintUsage(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "--no-pager    Do not pipe output into a pager") {
+		t.Errorf("Expected --no-pager flag, got:\n%s", out.String())
+	}
+}
+
+// markerReader 模拟终端输入，读取时记录标记文件是否已经存在
+type markerReader struct {
+	ttyReader
+	marker  string
+	started *bool
+}
+
+func (r markerReader) Read(b []byte) (int, error) {
+	if _, err := os.Stat(r.marker); err == nil {
+		*r.started = true
+	}
+	return r.ttyReader.Read(b)
+}
+
+func TestProgram_PagerAfterConfirm(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "started")
+	prog, out := newPagerProgram(t, "touch "+marker+"\nsed 's/^/| /'")
+	stderr := &bytes.Buffer{}
+	prog.SetStderr(stderr)
+
+	var env string
+	purgeCmd := NewCommand("purge", "Purge the log")
+	purgeCmd.Pager = true
+	purgeCmd.Dangerous = true
+	purgeCmd.Interactive = true
+	purgeCmd.Flags.StringVar(&env, "env", "", "Environment")
+	purgeCmd.Params = []Param{{Name: "env", Required: true}}
+	purgeCmd.Action = func(ctx context.Context, cmd *Command) error {
+		_, err := fmt.Fprintf(cmd.Stdout(), "purged %s\n", env)
+		return err
+	}
+	prog.Commands = append(prog.Commands, purgeCmd)
+
+	// 询问和确认都在分页器启动前完成
+	var started bool
+	prog.SetStdin(markerReader{ttyReader{strings.NewReader("prod\ny\n")}, marker, &started})
+	if err := prog.Run([]string{"testapp", "purge"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if started {
+		t.Error("Expected the pager to start after the prompts")
+	}
+	if !strings.Contains(stderr.String(), "Environment: ") || !strings.Contains(stderr.String(), "purge") {
+		t.Errorf("Expected prompts on stderr, got %q", stderr.String())
+	}
+	if out.String() != "| purged prod\n" {
+		t.Errorf("Expected action output through the pager, got %q", out.String())
+	}
+
+	// 拒绝确认时不启动分页器
+	if err := os.Remove(marker); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	prog.SetStdin(ttyReader{strings.NewReader("prod\nn\n")})
+	var ce *ConfirmError
+	if err := prog.Run([]string{"testapp", "purge"}); !errors.As(err, &ce) {
+		t.Fatalf("Expected ConfirmError, got %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("Expected no pager when the command is not confirmed")
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected no output, got %q", out.String())
+	}
+}
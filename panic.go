@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// PanicError 表示命令执行过程中发生并被恢复的 panic
+//
+// 启用 Program.RecoverPanics 后，Action 中的 panic 会被转换为 *PanicError 返回，
+// 而不是让进程带着原始堆栈崩溃。
+type PanicError struct {
+	Command    string // 发生 panic 的命令名称
+	Value      any    // recover() 得到的值
+	Stack      []byte // 发生 panic 时的 goroutine 堆栈
+	ReportPath string // 崩溃报告文件路径（未写入时为空）
+}
+
+// Error 实现 error 接口
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in command %q: %v", e.Command, e.Value)
+}
+
+// Unwrap 当 panic 的值本身是 error 时返回它
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// ExitCode 返回与 Go 运行时 panic 一致的退出码 2
+func (e *PanicError) ExitCode() int {
+	return 2
+}
+
+// recoverPanic 恢复 panic 并将其转换为 *PanicError
+//
+// 必须通过 defer 直接调用。会打印友好的提示信息，并在可能时写入崩溃报告。
+func (p *Program) recoverPanic(cmd *Command, args []string, errp *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	perr := &PanicError{
+		Command: cmd.Name,
+		Value:   r,
+		Stack:   debug.Stack(),
+	}
+
+	path, werr := p.writeCrashReport(perr, cmd.Flags, args)
+	if werr == nil {
+		perr.ReportPath = path
+	}
+
+	st := cmd.Styles(p.Stderr())
+	var b []byte
+	b = fmt.Appendf(b, "%s\n", st.Error.Render(fmt.Sprintf("%s: internal error: %v", p.Name, r)))
+	if werr == nil {
+		b = fmt.Appendf(b, "This is a bug. A crash report has been written to %s\n", path)
+	} else {
+		b = fmt.Appendf(b, "This is a bug. Failed to write crash report: %v\n", werr)
+	}
+	_, _ = p.Stderr().Write(b)
+
+	*errp = perr
+}
+
+// crashReportDir 获取崩溃报告目录
+func (p *Program) crashReportDir() string {
+	if p.CrashReportDir != "" {
+		return p.CrashReportDir
+	}
+	return os.TempDir()
+}
+
+// writeCrashReport 将崩溃报告写入 CrashReportDir，返回文件路径
+//
+// 报告包含脱敏后的命令行、程序版本、Go 运行时信息和堆栈。
+func (p *Program) writeCrashReport(perr *PanicError, fs *flag.FlagSet, args []string) (string, error) {
+	dir := p.crashReportDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(dir, p.Name+"-crash-*.log")
+	if err != nil {
+		return "", err
+	}
+
+	var b []byte
+	b = fmt.Appendf(b, "%s crash report\n\n", p.Name)
+	b = fmt.Appendf(b, "Time:       %s\n", p.Now().Format(time.RFC3339))
+	info := p.BuildInfo()
+	b = fmt.Appendf(b, "Version:    %s\n", info.Version)
+	if info.Revision != "" {
+		b = fmt.Appendf(b, "Revision:   %s\n", info.Revision)
+	}
+	b = fmt.Appendf(b, "Command:    %s\n", strings.Join(p.redactArgs(fs, args), " "))
+	b = fmt.Appendf(b, "Go version: %s\n", runtime.Version())
+	b = fmt.Appendf(b, "Platform:   %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	b = fmt.Appendf(b, "CPUs:       %d\n", runtime.NumCPU())
+	b = fmt.Appendf(b, "\nPanic: %v\n\n", perr.Value)
+	b = append(b, perr.Stack...)
+
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestProgram_RecoverPanics(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.RecoverPanics = true
+	prog.CrashReportDir = t.TempDir()
+	buf := &bytes.Buffer{}
+	prog.SetOutput(buf)
+
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Flags.String("token", "", "API token")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		panic("boom")
+	}
+	prog.Commands = []*Command{testCmd}
+
+	err := prog.Run([]string{"testapp", "test", "-token", "s3cr3t"})
+
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Expected *PanicError, got %v", err)
+	}
+	if perr.Value != "boom" {
+		t.Errorf("Expected panic value 'boom', got %v", perr.Value)
+	}
+	if perr.Command != "test" {
+		t.Errorf("Expected command 'test', got '%s'", perr.Command)
+	}
+	if !bytes.Contains(perr.Stack, []byte("panic")) {
+		t.Error("Expected stack trace to be captured")
+	}
+	if ExitCode(err) != 2 {
+		t.Errorf("Expected exit code 2, got %d", ExitCode(err))
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "internal error: boom") {
+		t.Errorf("Expected friendly message, got: %s", output)
+	}
+	if !strings.Contains(output, perr.ReportPath) {
+		t.Errorf("Expected message to mention report path, got: %s", output)
+	}
+
+	report, rerr := os.ReadFile(perr.ReportPath)
+	if rerr != nil {
+		t.Fatalf("Failed to read crash report: %v", rerr)
+	}
+	for _, want := range []string{"Version:    1.0.0", "testapp test -token ***", "Go version:", "Panic: boom"} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("Expected crash report to contain %q, got:\n%s", want, report)
+		}
+	}
+	if strings.Contains(string(report), "s3cr3t") {
+		t.Error("Expected secret value to be redacted from crash report")
+	}
+}
+
+func TestProgram_RecoverPanicsErrorValue(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.RecoverPanics = true
+	prog.CrashReportDir = t.TempDir()
+	prog.SetOutput(&bytes.Buffer{})
+
+	panicErr := errors.New("nil map")
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		panic(panicErr)
+	}
+	prog.Commands = []*Command{testCmd}
+
+	err := prog.Run([]string{"testapp", "test"})
+	if !errors.Is(err, panicErr) {
+		t.Errorf("Expected error to wrap the panic value, got %v", err)
+	}
+}
+
+func TestProgram_RecoverPanicsReportFailure(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.RecoverPanics = true
+	// 使用普通文件作为目录，使报告写入失败
+	file, err := os.CreateTemp(t.TempDir(), "not-a-dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+	prog.CrashReportDir = file.Name()
+	buf := &bytes.Buffer{}
+	prog.SetOutput(buf)
+
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		panic("boom")
+	}
+	prog.Commands = []*Command{testCmd}
+
+	err = prog.Run([]string{"testapp", "test"})
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Expected *PanicError, got %v", err)
+	}
+	if perr.ReportPath != "" {
+		t.Errorf("Expected empty report path, got '%s'", perr.ReportPath)
+	}
+	if !strings.Contains(buf.String(), "Failed to write crash report") {
+		t.Errorf("Expected failure notice, got: %s", buf.String())
+	}
+}
+
+func TestProgram_PanicsNotRecoveredByDefault(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SetOutput(&bytes.Buffer{})
+
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		panic("boom")
+	}
+	prog.Commands = []*Command{testCmd}
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("Expected panic to propagate, got %v", r)
+		}
+	}()
+	_ = prog.Run([]string{"testapp", "test"})
+	t.Error("Expected Run to panic")
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PromptType 交互式询问的方式
+type PromptType int
+
+// 询问方式
+const (
+	PromptText        PromptType = iota // 文本输入（支持默认值）
+	PromptPassword                      // 密码输入（关闭回显）
+	PromptConfirm                       // 是/否确认
+	PromptSelect                        // 从 Options 中单选
+	PromptMultiSelect                   // 从 Options 中多选
+)
+
+// Param 声明命令需要的值（标志或位置参数）
+//
+// 必需的值缺失时，如果命令启用了 Interactive 且可以询问（见 Command.CanPrompt），
+// RunContext 会交互式地询问用户；否则返回 *MissingParamError。
+type Param struct {
+	Name       string     // 标志名称；Positional 为 true 时为位置参数名称（用于帮助和提示）
+	Positional bool       // 是否为位置参数（按在 Params 中的声明顺序对应）
+	Required   bool       // 是否必需
+	Prompt     string     // 询问时的提示文本（默认使用标志的 Usage 或 Name）
+	Type       PromptType // 询问方式（默认 PromptText）
+	Default    string     // 询问时的默认值（多选时以逗号分隔）
+	Options    []string   // 可选值（PromptSelect、PromptMultiSelect）
+}
+
+// MissingParamError 表示缺少必需的标志或位置参数
+type MissingParamError struct {
+	Param Param
+}
+
+// Error 实现 error 接口
+func (e *MissingParamError) Error() string {
+	if e.Param.Positional {
+		return fmt.Sprintf("missing required argument: <%s>", e.Param.Name)
+	}
+	return fmt.Sprintf("missing required flag: -%s", e.Param.Name)
+}
+
+// resolveParams 检查必需的值，缺失时交互式询问或返回错误
+func (c *Command) resolveParams() error {
+	set := make(map[string]bool)
+	c.Flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	args := slices.Clone(c.Flags.Args())
+	prompted := false
+	var skipped []Param // 缺失的可选位置参数
+	pos := 0
+	for _, p := range c.Params {
+		if p.Positional {
+			if pos < len(args) {
+				pos++
+				continue
+			}
+			if !p.Required {
+				skipped = append(skipped, p)
+				continue
+			}
+			if !c.CanPrompt() {
+				return &MissingParamError{Param: p}
+			}
+			// 位置参数按声明顺序对应，先询问前面缺失的可选参数，使每个值位于声明的位置
+			for _, q := range append(skipped, p) {
+				values, err := c.askParam(q)
+				if err != nil {
+					return err
+				}
+				args = append(args, values...)
+			}
+			skipped = nil
+			pos = len(args)
+			prompted = true
+			continue
+		}
+
+		if c.Flags.Lookup(p.Name) == nil {
+			return fmt.Errorf("param %q does not match any flag of command %q", p.Name, c.Name)
+		}
+		if !p.Required || set[p.Name] {
+			continue
+		}
+		values, err := c.askParam(p)
+		if err != nil {
+			return err
+		}
+		if err := c.Flags.Set(p.Name, strings.Join(values, ",")); err != nil {
+			return fmt.Errorf("invalid value for -%s: %w", p.Name, err)
+		}
+	}
+
+	if prompted {
+		// 重新解析以便 Flags.Args() 包含询问得到的位置参数
+		return c.Flags.Parse(append([]string{"--"}, args...))
+	}
+	return nil
+}
+
+// askParam 交互式询问参数的值
+func (c *Command) askParam(p Param) ([]string, error) {
+	if !c.CanPrompt() {
+		return nil, &MissingParamError{Param: p}
+	}
+
+	label := p.Prompt
+	if label == "" && !p.Positional {
+		if f := c.Flags.Lookup(p.Name); f != nil {
+			label = f.Usage
+		}
+	}
+	if label == "" {
+		label = p.Name
+	}
+
+	pr := c.Prompter()
+	switch p.Type {
+	case PromptPassword:
+		v, err := pr.Password(label)
+		return []string{v}, err
+	case PromptConfirm:
+		def, _ := strconv.ParseBool(p.Default)
+		v, err := pr.Confirm(label, def)
+		return []string{strconv.FormatBool(v)}, err
+	case PromptSelect:
+		v, err := pr.Select(label, p.Options, p.Default)
+		return []string{v}, err
+	case PromptMultiSelect:
+		var defs []string
+		if p.Default != "" {
+			defs = strings.Split(p.Default, ",")
+		}
+		return pr.MultiSelect(label, p.Options, defs)
+	default:
+		v, err := pr.Text(label, p.Default)
+		return []string{v}, err
+	}
+}
+
+// usageArgs 返回帮助中位置参数的写法，如 " <name> [path]"
+func (c *Command) usageArgs() string {
+	var b strings.Builder
+	for _, p := range c.Params {
+		if !p.Positional {
+			continue
+		}
+		if p.Required {
+			fmt.Fprintf(&b, " <%s>", p.Name)
+		} else {
+			fmt.Fprintf(&b, " [%s]", p.Name)
+		}
+	}
+	return b.String()
+}
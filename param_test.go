@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCommand_RequiredFlagMissing(t *testing.T) {
+	cmd := NewCommand("deploy", "Deploy")
+	cmd.Flags.String("env", "", "Target environment")
+	cmd.Params = []Param{{Name: "env", Required: true}}
+	cmd.SetOutput(&bytes.Buffer{})
+	cmd.Action = func(ctx context.Context, c *Command) error {
+		return errors.New("should not be called")
+	}
+
+	err := cmd.Run([]string{})
+
+	var missing *MissingParamError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected *MissingParamError, got %v", err)
+	}
+	if err.Error() != "missing required flag: -env" {
+		t.Errorf("Unexpected error message: %s", err)
+	}
+}
+
+func TestCommand_RequiredFlagProvided(t *testing.T) {
+	var env string
+	cmd := NewCommand("deploy", "Deploy")
+	cmd.Flags.StringVar(&env, "env", "", "Target environment")
+	cmd.Params = []Param{{Name: "env", Required: true}}
+
+	if err := cmd.Run([]string{"-env", "prod"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if env != "prod" {
+		t.Errorf("Expected env 'prod', got '%s'", env)
+	}
+}
+
+func TestCommand_PromptForMissingValues(t *testing.T) {
+	var env, password string
+	var services string
+	var force bool
+	cmd := NewCommand("deploy", "Deploy")
+	cmd.Interactive = true
+	cmd.Flags.StringVar(&env, "env", "", "Target environment")
+	cmd.Flags.StringVar(&password, "password", "", "Password")
+	cmd.Flags.StringVar(&services, "services", "", "Services")
+	cmd.Flags.BoolVar(&force, "force", false, "Force")
+	cmd.Params = []Param{
+		{Name: "env", Required: true, Type: PromptSelect, Options: []string{"staging", "prod"}},
+		{Name: "password", Required: true, Type: PromptPassword},
+		{Name: "services", Required: true, Type: PromptMultiSelect, Options: []string{"web", "db"}},
+		{Name: "force", Required: true, Type: PromptConfirm},
+		{Name: "target", Positional: true, Required: true, Prompt: "Target host", Default: "localhost"},
+	}
+	stderr := &bytes.Buffer{}
+	cmd.SetStderr(stderr)
+	cmd.SetStdin(ttyReader{strings.NewReader("2\nhunter2\n1,2\ny\n\n")})
+
+	var args []string
+	cmd.Action = func(ctx context.Context, c *Command) error {
+		args = c.Flags.Args()
+		return nil
+	}
+
+	if err := cmd.Run([]string{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if env != "prod" || password != "hunter2" || services != "web,db" || !force {
+		t.Errorf("Unexpected prompted values: env=%q password=%q services=%q force=%v", env, password, services, force)
+	}
+	if !slices.Equal(args, []string{"localhost"}) {
+		t.Errorf("Expected prompted positional argument, got %v", args)
+	}
+	if !strings.Contains(stderr.String(), "Target environment:") || !strings.Contains(stderr.String(), "Target host [localhost]: ") {
+		t.Errorf("Expected prompts on stderr, got %q", stderr.String())
+	}
+}
+
+func TestCommand_PromptPositionalAtDeclaredIndex(t *testing.T) {
+	cmd := NewCommand("copy", "Copy files")
+	cmd.Interactive = true
+	cmd.Params = []Param{
+		{Name: "src", Positional: true, Required: true},
+		{Name: "mode", Positional: true, Default: "0644"},
+		{Name: "dst", Positional: true, Required: true},
+	}
+	cmd.SetStderr(&bytes.Buffer{})
+	cmd.SetStdin(ttyReader{strings.NewReader("\n/tmp/b\n")})
+
+	var args []string
+	cmd.Action = func(ctx context.Context, c *Command) error {
+		args = c.Flags.Args()
+		return nil
+	}
+
+	if err := cmd.Run([]string{"a"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !slices.Equal(args, []string{"a", "0644", "/tmp/b"}) {
+		t.Errorf("Expected prompted values at their declared index, got %v", args)
+	}
+}
+
+func TestCommand_PromptDisabledOnNonTerminal(t *testing.T) {
+	cmd := NewCommand("deploy", "Deploy")
+	cmd.Interactive = true
+	cmd.Params = []Param{{Name: "target", Positional: true, Required: true}}
+	cmd.SetStdin(strings.NewReader("localhost\n"))
+
+	err := cmd.Run([]string{})
+	if err == nil || err.Error() != "missing required argument: <target>" {
+		t.Errorf("Expected missing argument error, got %v", err)
+	}
+}
+
+func TestCommand_ParamUnknownFlag(t *testing.T) {
+	cmd := NewCommand("deploy", "Deploy")
+	cmd.Params = []Param{{Name: "nope", Required: true}}
+
+	if err := cmd.Run([]string{}); err == nil || !strings.Contains(err.Error(), "does not match any flag") {
+		t.Errorf("Expected error for undefined flag, got %v", err)
+	}
+}
+
+func TestCommand_PrintUsagePositionalParams(t *testing.T) {
+	cmd := NewCommand("copy", "Copy files")
+	cmd.Params = []Param{
+		{Name: "src", Positional: true, Required: true},
+		{Name: "dst", Positional: true},
+	}
+	buf := &bytes.Buffer{}
+	cmd.SetOutput(buf)
+
+	if err := cmd.PrintUsage(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Usage: copy [options] <src> [dst]") {
+		t.Errorf("Expected positional params in usage, got %q", buf.String())
+	}
+}
+
+func TestProgram_NoInputFlag(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SetOutput(&bytes.Buffer{})
+	prog.SetStdin(ttyReader{strings.NewReader("prod\n")})
+
+	var env string
+	deployCmd := NewCommand("deploy", "Deploy")
+	deployCmd.Interactive = true
+	deployCmd.Flags.StringVar(&env, "env", "", "Target environment")
+	deployCmd.Params = []Param{{Name: "env", Required: true}}
+	prog.Commands = []*Command{deployCmd}
+
+	for _, args := range [][]string{
+		{"testapp", "deploy", "--no-input"},
+		{"testapp", "--no-input", "deploy"},
+	} {
+		err := prog.Run(args)
+		var missing *MissingParamError
+		if !errors.As(err, &missing) {
+			t.Errorf("%v: expected *MissingParamError, got %v", args, err)
+		}
+	}
+
+	if err := prog.Run([]string{"testapp", "deploy"}); err != nil {
+		t.Fatalf("Expected prompt to succeed, got %v", err)
+	}
+	if env != "prod" {
+		t.Errorf("Expected prompted env 'prod', got '%s'", env)
+	}
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// pluginPrefix 返回插件可执行文件的名称前缀（<Name>-）
+func (p *Program) pluginPrefix() string {
+	return p.Name + "-"
+}
+
+// findPlugin 在 PATH 中查找命令对应的插件，未找到时返回空字符串
+func (p *Program) findPlugin(name string) string {
+	if !p.EnablePlugins || name == "" || isFlag(name) || strings.ContainsAny(name, `/\`) {
+		return ""
+	}
+	return p.lookPath(p.pluginPrefix() + name)
+}
+
+// plugins 返回 PATH 中的所有插件（命令名称 → 路径）
+//
+// 与命令、内置命令或别名同名的插件会被忽略；同名插件以 PATH 中靠前的为准。
+func (p *Program) plugins() map[string]string {
+	plugins := make(map[string]string)
+	if !p.EnablePlugins {
+		return plugins
+	}
+	aliases, _ := p.aliases()
+
+	prefix := p.pluginPrefix()
+	pathext := p.Getenv("PATHEXT")
+	for _, dir := range p.pathDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), prefix)
+			if !ok || entry.IsDir() {
+				continue
+			}
+			name, ok = executableName(name, entry, pathext)
+			if !ok || name == "" {
+				continue
+			}
+			if _, seen := plugins[name]; seen {
+				continue
+			}
+			if _, isAlias := aliases[name]; isAlias || p.get(name) != nil {
+				continue
+			}
+			plugins[name] = filepath.Join(dir, entry.Name())
+		}
+	}
+	return plugins
+}
+
+// executableName 判断文件是否可执行，并返回去掉可执行扩展名（Windows）后的名称
+func executableName(name string, entry os.DirEntry, pathext string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		exts := strings.Split(strings.ToLower(pathext), ";")
+		if ext == "" || !slices.Contains(append(exts, ".exe"), ext) {
+			return "", false
+		}
+		return strings.TrimSuffix(name, filepath.Ext(name)), true
+	}
+	info, err := entry.Info()
+	if err != nil {
+		return "", false
+	}
+	return name, info.Mode().Perm()&0o111 != 0
+}
+
+// runPlugin 执行插件，传入剩余参数和描述主程序的环境变量
+//
+// 插件的输入输出与程序相同；收到的信号会转发给插件，插件的退出码原样返回（见 ExitCode）。
+func (p *Program) runPlugin(ctx context.Context, path string, args []string) error {
+	if !p.HandleSignals {
+		var stop func()
+		ctx, stop = p.cancelOnSignals(ctx)
+		defer stop()
+	}
+
+	c := exec.CommandContext(ctx, path, args...)
+	c.Env = append(p.Environ(), p.pluginEnv()...)
+	return p.runProcess(ctx, c)
+}
+
+// pluginEnv 返回传给插件的环境变量
+//
+// <NAME>_VERSION 为程序版本，<NAME>_EXECUTABLE 为主程序路径，
+// 设置了 ConfigFile 时 <NAME>_CONFIG 为配置文件路径。<NAME> 见 EnvPrefix。
+func (p *Program) pluginEnv() []string {
+	prefix := p.EnvPrefix()
+	env := []string{prefix + "_VERSION=" + p.Version}
+	if exe, err := p.executablePath(); err == nil {
+		env = append(env, prefix+"_EXECUTABLE="+exe)
+	}
+	if p.ConfigFile != "" {
+		env = append(env, prefix+"_CONFIG="+p.Path(p.ConfigFile))
+	}
+	return env
+}
+
+// EnvPrefix 返回程序相关环境变量的前缀：大写的程序名称，非字母数字的字符替换为下划线
+//
+// 例如 "my-app" 对应 "MY_APP"。
+func (p *Program) EnvPrefix() string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, p.Name)
+}
+
+// appendPluginUsage 在帮助中追加 PLUGIN COMMANDS 部分
+func (p *Program) appendPluginUsage(b []byte, st Theme) []byte {
+	plugins := p.plugins()
+	if len(plugins) == 0 {
+		return b
+	}
+
+	names := make([]string, 0, len(plugins))
+	maxLen := 0
+	for name := range plugins {
+		names = append(names, name)
+		maxLen = max(maxLen, len(name))
+	}
+	slices.Sort(names)
+
+	b = fmt.Appendf(b, "\n%s\n", st.Heading.Render("PLUGIN COMMANDS:"))
+	for _, name := range names {
+		b = fmt.Appendf(b, "    %s    %s\n", st.Command.Render(fmt.Sprintf("%-*s", maxLen, name)), plugins[name])
+	}
+	return b
+}
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// setupPlugins 在临时目录中创建插件脚本并将其加入 PATH
+func setupPlugins(t *testing.T, scripts map[string]string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts use sh")
+	}
+	dir := t.TempDir()
+	for name, body := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func newPluginProgram() (*Program, *syncBuffer) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.EnablePlugins = true
+	out := &syncBuffer{}
+	prog.SetOutput(out)
+
+	deployCmd := NewCommand("deploy", "Deploy the application")
+	deployCmd.Action = func(ctx context.Context, cmd *Command) error {
+		_, err := cmd.Stdout().Write([]byte("builtin deploy\n"))
+		return err
+	}
+	prog.Commands = []*Command{deployCmd}
+	return prog, out
+}
+
+func TestProgram_Plugins(t *testing.T) {
+	setupPlugins(t, map[string]string{
+		"testapp-hello":  `echo "hello $TESTAPP_VERSION $TESTAPP_CONFIG $*"; exit 4` + "\n",
+		"testapp-deploy": "echo plugin deploy\n",
+	})
+	prog, out := newPluginProgram()
+	prog.ConfigFile = "/etc/testapp.conf"
+
+	err := prog.Run([]string{"testapp", "hello", "-h", "a b"})
+	if ExitCode(err) != 4 {
+		t.Errorf("Expected plugin exit code 4, got %v", err)
+	}
+	if out.String() != "hello 1.0.0 /etc/testapp.conf -h a b\n" {
+		t.Errorf("Unexpected plugin output: %q", out.String())
+	}
+
+	// 插件不会遮蔽真正的命令
+	out.buf.Reset()
+	if err := prog.Run([]string{"testapp", "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "builtin deploy\n" {
+		t.Errorf("Expected registered command to win, got %q", out.String())
+	}
+
+	// help <插件> 调用插件的 --help
+	out.buf.Reset()
+	_ = prog.Run([]string{"testapp", "help", "hello"})
+	if !strings.Contains(out.String(), "--help") {
+		t.Errorf("Expected help to be delegated to plugin, got %q", out.String())
+	}
+
+	// 未启用时按未知命令处理
+	prog.EnablePlugins = false
+	if err := prog.Run([]string{"testapp", "hello"}); err == nil || err.Error() != "unknown command: hello" {
+		t.Errorf("Expected unknown command when plugins are disabled, got %v", err)
+	}
+}
+
+func TestProgram_PluginsSkipRelativePath(t *testing.T) {
+	dir := setupPlugins(t, map[string]string{"testapp-hello": "echo hello\n"})
+	if err := os.MkdirAll(filepath.Join(dir, "bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(filepath.Join(dir, "testapp-hello"), filepath.Join(dir, "bin", "testapp-world")); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, _ := newPluginProgram()
+	prog.SetDir(dir)
+	prog.SetEnviron([]string{"PATH=" + strings.Join([]string{"", ".", "bin"}, string(os.PathListSeparator))})
+
+	if plugins := prog.plugins(); len(plugins) != 0 {
+		t.Errorf("Expected relative PATH entries to be skipped, got %v", plugins)
+	}
+	if path := prog.findPlugin("hello"); path != "" {
+		t.Errorf("Expected plugin in the working directory not to be found, got %s", path)
+	}
+}
+
+func TestProgram_PluginEnv(t *testing.T) {
+	setupPlugins(t, map[string]string{
+		"testapp-env": `echo "$TESTAPP_EXECUTABLE $TESTAPP_CONFIG"` + "\n",
+	})
+	prog, out := newPluginProgram()
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "testapp")
+	if err := os.WriteFile(exe, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	prog.executable = func() (string, error) { return exe, nil }
+	prog.SetDir(dir)
+	prog.ConfigFile = "testapp.conf"
+
+	if err := prog.Run([]string{"testapp", "env"}); err != nil {
+		t.Fatal(err)
+	}
+	want := exe + " " + filepath.Join(dir, "testapp.conf") + "\n"
+	if out.String() != want {
+		t.Errorf("Expected plugin environment %q, got %q", want, out.String())
+	}
+}
+
+func TestProgram_PluginUsage(t *testing.T) {
+	dir := setupPlugins(t, map[string]string{
+		"testapp-hello":  "true\n",
+		"testapp-deploy": "true\n",
+		"otherapp-nope":  "true\n",
+	})
+	if err := os.WriteFile(filepath.Join(dir, "testapp-data"), []byte("not executable"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prog, _ := newPluginProgram()
+	out := &bytes.Buffer{}
+	prog.SetStdout(out)
+
+	if err := prog.PrintUsage(); err != nil {
+		t.Fatal(err)
+	}
+	want := "PLUGIN COMMANDS:\n    hello    " + filepath.Join(dir, "testapp-hello") + "\n"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("Expected plugin section %q, got:\n%s", want, out.String())
+	}
+	for _, unwanted := range []string{"testapp-deploy", "testapp-data", "nope"} {
+		if strings.Contains(out.String(), unwanted) {
+			t.Errorf("Expected %q not to be listed, got:\n%s", unwanted, out.String())
+		}
+	}
+}
+
+func TestProgram_PluginSignals(t *testing.T) {
+	setupPlugins(t, map[string]string{
+		"testapp-wait": "trap 'echo got TERM; exit 7' TERM\necho ready\nwhile :; do sleep 0.05; done\n",
+	})
+	prog, out := newPluginProgram()
+	registered := fakeSignals(prog)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- prog.Run([]string{"testapp", "wait"})
+	}()
+
+	sigCh := <-registered
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), "ready") {
+		if time.Now().After(deadline) {
+			t.Fatal("plugin did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	sigCh <- syscall.SIGTERM
+
+	select {
+	case err := <-done:
+		if ExitCode(err) != 7 {
+			t.Errorf("Expected plugin exit code 7, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("plugin did not stop after signal")
+	}
+	if !strings.Contains(out.String(), "got TERM") {
+		t.Errorf("Expected signal to be forwarded, got %q", out.String())
+	}
+}
+
+func TestProgram_EnvPrefix(t *testing.T) {
+	tests := map[string]string{
+		"myapp":   "MYAPP",
+		"my-app":  "MY_APP",
+		"My.App2": "MY_APP2",
+	}
+	for name, want := range tests {
+		if got := NewProgram(name, "").EnvPrefix(); got != want {
+			t.Errorf("EnvPrefix(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,412 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 插件协议的方法名称
+//
+// 主程序与插件之间通过插件的标准输入输出交换以换行分隔的 JSON-RPC 2.0 消息：
+//
+//	→ {"jsonrpc":"2.0","id":1,"method":"describe"}
+//	← {"jsonrpc":"2.0","id":1,"result":{"commands":[...]}}
+//	→ {"jsonrpc":"2.0","id":1,"method":"run","params":{"command":"greet","flags":{"name":["ada"]},"args":[]}}
+//	← {"jsonrpc":"2.0","method":"output","params":{"stream":"stdout","data":"aGVsbG8gYWRhCg=="}}
+//	← {"jsonrpc":"2.0","id":1,"result":{"exitCode":0}}
+//
+// 主程序每次调用都会启动新的插件进程，执行期间 context 被取消时发送 cancel 通知；
+// 插件在标准输入关闭后退出。
+const (
+	rpcDescribe = "describe"
+	rpcRun      = "run"
+	rpcOutput   = "output"
+	rpcCancel   = "cancel"
+)
+
+// rpcMessage JSON-RPC 2.0 消息（请求、响应或通知）
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError JSON-RPC 错误，Data.ExitCode 为命令的退出码
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		ExitCode int `json:"exitCode,omitempty"`
+	} `json:"data"`
+}
+
+// Error 实现 error 接口
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// ExitCode 实现 ExitCoder 接口
+func (e *rpcError) ExitCode() int {
+	if e.Data.ExitCode != 0 {
+		return e.Data.ExitCode
+	}
+	return 1
+}
+
+// rpcErrorCommandFailed 命令执行失败的错误码（JSON-RPC 保留范围之外）
+const rpcErrorCommandFailed = 1
+
+// pluginSpec describe 方法的结果
+type pluginSpec struct {
+	Commands []commandSpec `json:"commands"`
+}
+
+// commandSpec 插件命令的描述
+type commandSpec struct {
+	Name        string      `json:"name"`
+	Usage       string      `json:"usage,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Flags       []flagSpec  `json:"flags,omitempty"`
+	Params      []paramSpec `json:"params,omitempty"`
+	Interactive bool        `json:"interactive,omitempty"`
+	HideOutput  bool        `json:"hideOutput,omitempty"` // 不注册 -output/-o 标志（见 HideOutputFlag）
+}
+
+// flagSpec 插件命令标志的描述，Type 为 string、bool、int、float 或 duration
+//
+// Repeated 为 true 时标志可以出现多次，每次的值都会传给插件。
+type flagSpec struct {
+	Name     string `json:"name"`
+	Usage    string `json:"usage,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Default  string `json:"default,omitempty"`
+	Repeated bool   `json:"repeated,omitempty"`
+}
+
+// paramSpec 插件命令参数的描述（对应 Param），Type 为 text、password、confirm、select 或 multiselect
+type paramSpec struct {
+	Name       string   `json:"name"`
+	Positional bool     `json:"positional,omitempty"`
+	Required   bool     `json:"required,omitempty"`
+	Prompt     string   `json:"prompt,omitempty"`
+	Type       string   `json:"type,omitempty"`
+	Default    string   `json:"default,omitempty"`
+	Options    []string `json:"options,omitempty"`
+}
+
+// promptTypeNames 询问方式在协议中的名称
+var promptTypeNames = []string{
+	PromptText:        "text",
+	PromptPassword:    "password",
+	PromptConfirm:     "confirm",
+	PromptSelect:      "select",
+	PromptMultiSelect: "multiselect",
+}
+
+// runParams run 方法的参数
+//
+// Flags 只包含用户设置过的标志，值按出现顺序排列（非重复标志只有一个值）；
+// Output、Timeout 为主程序内置的 -output、-timeout 标志的值，
+// StdoutTerminal、StderrTerminal 表示主程序的输出流是否为终端。
+type runParams struct {
+	Command        string              `json:"command"`
+	Flags          map[string][]string `json:"flags,omitempty"`
+	Args           []string            `json:"args"`
+	Output         string              `json:"output,omitempty"`
+	Timeout        string              `json:"timeout,omitempty"`
+	StdoutTerminal bool                `json:"stdoutTerminal,omitempty"`
+	StderrTerminal bool                `json:"stderrTerminal,omitempty"`
+}
+
+// runResult run 方法的结果
+type runResult struct {
+	ExitCode int `json:"exitCode"`
+}
+
+// outputParams output 通知的参数，Stream 为 stdout 或 stderr，Data 在 JSON 中为 base64 编码
+type outputParams struct {
+	Stream string `json:"stream"`
+	Data   []byte `json:"data"`
+}
+
+// LoadPlugin 启动协议插件，将其声明的命令注册到 Commands 并返回
+//
+// 插件以 args 为参数启动，通过标准输入输出使用 JSON-RPC 协议通信（Go 插件可以使用 ServePlugin）。
+// 注册的命令是普通的 Command：帮助、补全、标志校验和必需参数的询问都在主程序中完成，
+// 每次执行时启动插件并传入解析后的标志和参数，插件的输出实时写到命令的输出流。
+// 插件命令与已有命令同名时返回错误，且不注册任何命令。
+func (p *Program) LoadPlugin(ctx context.Context, path string, args ...string) ([]*Command, error) {
+	var spec pluginSpec
+	if err := p.callPlugin(ctx, path, args, p.Stdout(), p.Stderr(), rpcDescribe, nil, &spec); err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", path, err)
+	}
+
+	var commands []*Command
+	for _, cs := range spec.Commands {
+		if cs.Name == "" || p.get(cs.Name) != nil || slices.ContainsFunc(commands, func(c *Command) bool { return c.Name == cs.Name }) {
+			return nil, fmt.Errorf("plugin %s: invalid or duplicate command name %q", path, cs.Name)
+		}
+		cmd, err := p.pluginCommand(path, args, cs)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: command %s: %w", path, cs.Name, err)
+		}
+		commands = append(commands, cmd)
+	}
+	p.Commands = append(p.Commands, commands...)
+	return commands, nil
+}
+
+// pluginCommand 根据描述创建在插件中执行的命令
+func (p *Program) pluginCommand(path string, pluginArgs []string, cs commandSpec) (*Command, error) {
+	cmd := NewCommand(cs.Name, cs.Usage)
+	cmd.Description = cs.Description
+	cmd.Interactive = cs.Interactive
+	cmd.HideOutputFlag = cs.HideOutput
+
+	for _, fs := range cs.Flags {
+		v := &pluginFlagValue{typ: fs.Type, repeated: fs.Repeated}
+		if fs.Type == "" {
+			v.typ = "string"
+		}
+		if !slices.Contains(pluginFlagTypes, v.typ) {
+			return nil, fmt.Errorf("unknown type %q for -%s", fs.Type, fs.Name)
+		}
+		if fs.Default != "" {
+			if err := v.Set(fs.Default); err != nil {
+				return nil, fmt.Errorf("invalid default for -%s: %w", fs.Name, err)
+			}
+		}
+		v.def = v.values
+		cmd.Flags.Var(v, fs.Name, fs.Usage)
+	}
+
+	for _, ps := range cs.Params {
+		typ := PromptText
+		if ps.Type != "" {
+			i := slices.Index(promptTypeNames, ps.Type)
+			if i < 0 {
+				return nil, fmt.Errorf("unknown param type %q", ps.Type)
+			}
+			typ = PromptType(i)
+		}
+		cmd.Params = append(cmd.Params, Param{
+			Name:       ps.Name,
+			Positional: ps.Positional,
+			Required:   ps.Required,
+			Prompt:     ps.Prompt,
+			Type:       typ,
+			Default:    ps.Default,
+			Options:    ps.Options,
+		})
+	}
+
+	cmd.Action = func(ctx context.Context, inv *Command) error {
+		params := runParams{
+			Command:        inv.Name,
+			Flags:          map[string][]string{},
+			Args:           inv.Flags.Args(),
+			Output:         inv.outputValue,
+			StdoutTerminal: isTerminal(inv.Stdout()),
+			StderrTerminal: isTerminal(inv.Stderr()),
+		}
+		if d := inv.effectiveTimeout(); d > 0 {
+			params.Timeout = d.String()
+		}
+		// 内置标志不是插件声明的标志，通过 Output、Timeout 单独传递
+		inv.Flags.Visit(func(f *flag.Flag) {
+			if v, ok := f.Value.(*pluginFlagValue); ok {
+				params.Flags[f.Name] = v.values
+			}
+		})
+		return p.callPlugin(ctx, path, pluginArgs, inv.Stdout(), inv.Stderr(), rpcRun, params, nil)
+	}
+	return cmd, nil
+}
+
+// callPlugin 启动插件并调用一个方法，插件的 output 通知写到 stdout、stderr
+func (p *Program) callPlugin(ctx context.Context, path string, args []string, stdout, stderr io.Writer, method string, params, result any) error {
+	c := exec.Command(p.Path(path), args...)
+	c.Env = append(p.Environ(), p.pluginEnv()...)
+	c.Dir = p.dir
+	c.Stderr = stderr
+	in, err := c.StdinPipe()
+	if err != nil {
+		return err
+	}
+	out, err := c.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	enc := json.NewEncoder(in)
+	send := func(msg rpcMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		msg.JSONRPC = "2.0"
+		return enc.Encode(msg)
+	}
+
+	// context 取消时通知插件，超过宽限期仍未退出则强制结束
+	stop := context.AfterFunc(ctx, func() {
+		_ = send(rpcMessage{Method: rpcCancel})
+		time.AfterFunc(p.shutdownTimeout(), func() {
+			_ = c.Process.Kill()
+		})
+	})
+
+	callErr := p.exchange(send, json.NewDecoder(out), stdout, stderr, method, params, result)
+	stop()
+	_ = in.Close()
+	waitErr := c.Wait()
+
+	if errors.Is(callErr, io.ErrUnexpectedEOF) {
+		// 插件在响应之前退出
+		var exitErr *exec.ExitError
+		if errors.As(waitErr, &exitErr) {
+			if sig := exitSignal(exitErr); sig != nil {
+				return &SignalError{Signal: sig}
+			}
+			return fmt.Errorf("plugin exited before responding: %w", waitErr)
+		}
+		return errors.New("plugin exited before responding")
+	}
+	var rpcErr *rpcError
+	if ctx.Err() != nil && (callErr == nil || errors.As(callErr, &rpcErr)) {
+		// 插件处理了取消，向调用方报告取消原因
+		return context.Cause(ctx)
+	}
+	return callErr
+}
+
+// exchange 发送请求并读取消息直到收到对应的响应
+func (p *Program) exchange(send func(rpcMessage) error, dec *json.Decoder, stdout, stderr io.Writer, method string, params, result any) error {
+	id := int64(1)
+	req := rpcMessage{ID: &id, Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = raw
+	}
+	if err := send(req); err != nil {
+		if isBrokenPipe(err) {
+			// 插件已经退出，由调用方根据退出状态报告错误
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	for {
+		var msg rpcMessage
+		if err := dec.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("invalid plugin message: %w", err)
+		}
+
+		if msg.Method == rpcOutput && msg.ID == nil {
+			var o outputParams
+			if err := json.Unmarshal(msg.Params, &o); err != nil {
+				return fmt.Errorf("invalid output notification: %w", err)
+			}
+			w := stdout
+			if o.Stream == "stderr" {
+				w = stderr
+			}
+			if _, err := w.Write(o.Data); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.ID == nil || *msg.ID != id {
+			continue
+		}
+
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				return fmt.Errorf("invalid plugin result: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
+// pluginFlagTypes 插件标志支持的类型
+var pluginFlagTypes = []string{"string", "bool", "int", "float", "duration"}
+
+// pluginFlagValue 插件命令标志的值，按声明的类型校验后以字符串形式传给插件
+type pluginFlagValue struct {
+	typ      string
+	repeated bool     // 可重复，每次出现追加一个值
+	def      []string // 默认值
+	values   []string
+}
+
+// String 实现 flag.Value 接口
+func (v *pluginFlagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return strings.Join(v.values, ",")
+}
+
+// Set 实现 flag.Value 接口
+func (v *pluginFlagValue) Set(s string) error {
+	var err error
+	switch v.typ {
+	case "bool":
+		_, err = strconv.ParseBool(s)
+	case "int":
+		_, err = strconv.ParseInt(s, 0, 64)
+	case "float":
+		_, err = strconv.ParseFloat(s, 64)
+	case "duration":
+		_, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s value %q", v.typ, s)
+	}
+	if v.repeated {
+		v.values = append(slices.Clip(v.values), s)
+	} else {
+		v.values = []string{s}
+	}
+	return nil
+}
+
+// IsBoolFlag 布尔标志可以省略值
+func (v *pluginFlagValue) IsBoolFlag() bool {
+	return v.typ == "bool"
+}
+
+// Reset 恢复默认值（见 flagResetter）
+func (v *pluginFlagValue) Reset() {
+	v.values = v.def
+}
+
+// cloneFlag 返回处于默认状态的副本（见 flagCloner）
+func (v *pluginFlagValue) cloneFlag() flag.Value {
+	return &pluginFlagValue{typ: v.typ, repeated: v.repeated, def: v.def, values: v.def}
+}
@@ -0,0 +1,302 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestHelperPlugin 作为测试插件进程运行（由 LoadPlugin 通过测试二进制启动）
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv("CLI_TEST_PLUGIN") != "1" {
+		return
+	}
+
+	var name string
+	var loud bool
+	var count int
+	greetCmd := NewCommand("greet", "Greet someone")
+	greetCmd.Description = "Print a greeting"
+	greetCmd.Flags.StringVar(&name, "name", "world", "Name to greet")
+	greetCmd.Flags.BoolVar(&loud, "loud", false, "Shout")
+	greetCmd.Flags.IntVar(&count, "count", 1, "Repeat count")
+	greetCmd.Action = func(ctx context.Context, cmd *Command) error {
+		msg := "hello " + name
+		if loud {
+			msg = strings.ToUpper(msg)
+		}
+		for range count {
+			fmt.Fprintln(cmd.Stdout(), msg, cmd.Flags.Args())
+		}
+		fmt.Fprintln(cmd.Stderr(), "greeted")
+		return nil
+	}
+
+	failCmd := NewCommand("fail", "Always fail")
+	failCmd.Action = func(ctx context.Context, cmd *Command) error {
+		return &testExitError{code: 5}
+	}
+
+	waitCmd := NewCommand("wait", "Wait for cancel")
+	waitCmd.Action = func(ctx context.Context, cmd *Command) error {
+		fmt.Fprintln(cmd.Stdout(), "ready")
+		<-ctx.Done()
+		fmt.Fprintln(cmd.Stderr(), "canceled")
+		return ctx.Err()
+	}
+
+	var env string
+	deployCmd := NewCommand("deploy", "Deploy")
+	deployCmd.Interactive = true
+	deployCmd.Flags.StringVar(&env, "env", "", "Target environment")
+	deployCmd.Params = []Param{
+		{Name: "env", Required: true, Type: PromptSelect, Options: []string{"staging", "production"}},
+	}
+	deployCmd.Action = func(ctx context.Context, cmd *Command) error {
+		fmt.Fprintln(cmd.Stdout(), "deploying to", env)
+		return nil
+	}
+
+	var tags plainList
+	infoCmd := NewCommand("info", "Report the invocation")
+	infoCmd.Flags.Var(&tags, "tag", "Tag (repeatable)")
+	infoCmd.Action = func(ctx context.Context, cmd *Command) error {
+		_, hasDeadline := ctx.Deadline()
+		return cmd.Print(map[string]any{
+			"tags":     []string(tags),
+			"deadline": hasDeadline,
+			"terminal": isTerminal(cmd.Stdout()),
+		})
+	}
+
+	if err := ServePlugin(greetCmd, failCmd, waitCmd, deployCmd, infoCmd); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func loadTestPlugin(t *testing.T) (*Program, *syncBuffer, *syncBuffer) {
+	t.Helper()
+	t.Setenv("CLI_TEST_PLUGIN", "1")
+	prog := NewProgram("testapp", "1.0.0")
+	stdout := &syncBuffer{}
+	stderr := &syncBuffer{}
+	prog.SetStdout(stdout)
+	prog.SetStderr(stderr)
+
+	cmds, err := prog.LoadPlugin(context.Background(), os.Args[0], "-test.run=^TestHelperPlugin$")
+	if err != nil {
+		t.Fatalf("LoadPlugin failed: %v", err)
+	}
+	if len(cmds) != 5 || len(prog.Commands) != 5 {
+		t.Fatalf("Expected 5 plugin commands, got %d", len(cmds))
+	}
+	return prog, stdout, stderr
+}
+
+func TestProgram_LoadPlugin(t *testing.T) {
+	prog, stdout, stderr := loadTestPlugin(t)
+
+	if err := prog.Run([]string{"testapp", "greet", "-name", "ada", "-loud", "-count", "2", "x", "y"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stdout.String() != "HELLO ADA [x y]\nHELLO ADA [x y]\n" {
+		t.Errorf("Unexpected output: %q", stdout.String())
+	}
+	if stderr.String() != "greeted\n" {
+		t.Errorf("Unexpected stderr: %q", stderr.String())
+	}
+
+	// 每次执行都使用默认值
+	stdout.buf.Reset()
+	if err := prog.Run([]string{"testapp", "greet"}); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "hello world []\n" {
+		t.Errorf("Expected default flag values, got %q", stdout.String())
+	}
+
+	// 帮助和标志校验在主程序中完成
+	stdout.buf.Reset()
+	if err := prog.Run([]string{"testapp", "help", "greet"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Print a greeting", "-count", "Repeat count (default 1)", "-loud"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Expected help to contain %q, got:\n%s", want, stdout.String())
+		}
+	}
+
+	stdout.buf.Reset()
+	if err := prog.Run([]string{"testapp", "greet", "-count", "many"}); err == nil {
+		t.Error("Expected invalid int flag to be rejected")
+	}
+	if stdout.String() != "" {
+		t.Errorf("Expected plugin not to run, got %q", stdout.String())
+	}
+}
+
+func TestProgram_LoadPluginForwardsInvocation(t *testing.T) {
+	prog, stdout, _ := loadTestPlugin(t)
+
+	if err := prog.Run([]string{"testapp", "info", "-tag", "a", "-tag", "b,c", "-o", "jsonl", "-timeout", "1m"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stdout.String() != `{"deadline":true,"tags":["a","b,c"],"terminal":false}`+"\n" {
+		t.Errorf("Unexpected output: %q", stdout.String())
+	}
+
+	// 主程序的标准输出为终端时插件同样使用表格输出
+	stdout.buf.Reset()
+	prog.SetStdout(ttyWriter{stdout})
+	if err := prog.Run([]string{"testapp", "info"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(stdout.String(), "terminal   true") {
+		t.Errorf("Expected table output with terminal=true, got %q", stdout.String())
+	}
+}
+
+func TestProgram_LoadPluginErrors(t *testing.T) {
+	prog, _, _ := loadTestPlugin(t)
+
+	err := prog.Run([]string{"testapp", "fail"})
+	if ExitCode(err) != 5 || err.Error() != "exit status 5" {
+		t.Errorf("Expected plugin error with exit code 5, got %v (%d)", err, ExitCode(err))
+	}
+
+	if _, err := prog.LoadPlugin(context.Background(), os.Args[0], "-test.run=^TestHelperPlugin$"); err == nil {
+		t.Error("Expected duplicate commands to be rejected")
+	}
+	if len(prog.Commands) != 5 {
+		t.Errorf("Expected no commands to be added on error, got %d", len(prog.Commands))
+	}
+
+	if _, err := prog.LoadPlugin(context.Background(), "/bin/sh", "-c", "exit 3"); err == nil || !strings.Contains(err.Error(), "exited before responding") {
+		t.Errorf("Expected error for non-protocol plugin, got %v", err)
+	}
+}
+
+func TestProgram_LoadPluginParams(t *testing.T) {
+	prog, stdout, stderr := loadTestPlugin(t)
+
+	prog.SetStdin(strings.NewReader(""))
+	var missing *MissingParamError
+	if err := prog.Run([]string{"testapp", "deploy"}); !errors.As(err, &missing) {
+		t.Errorf("Expected *MissingParamError, got %v", err)
+	}
+
+	// 主程序询问缺失的值后传给插件
+	prog.SetStdin(ttyReader{strings.NewReader("2\n")})
+	if err := prog.Run([]string{"testapp", "deploy"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stdout.String() != "deploying to production\n" {
+		t.Errorf("Unexpected output: %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "2) production") {
+		t.Errorf("Expected prompt on host stderr, got %q", stderr.String())
+	}
+}
+
+func TestProgram_LoadPluginCancel(t *testing.T) {
+	prog, stdout, stderr := loadTestPlugin(t)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	go func() {
+		for !strings.Contains(stdout.String(), "ready") {
+			time.Sleep(10 * time.Millisecond)
+		}
+		cancel(&SignalError{Signal: syscall.SIGTERM})
+	}()
+
+	err := prog.RunContext(ctx, []string{"testapp", "wait"})
+	if ExitCode(err) != 143 {
+		t.Errorf("Expected cancel cause to be returned, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), "canceled") {
+		t.Errorf("Expected plugin to observe cancel, got %q", stderr.String())
+	}
+}
+
+func TestPluginFlagValue(t *testing.T) {
+	v := &pluginFlagValue{typ: "duration", def: []string{"1s"}, values: []string{"1s"}}
+	if err := v.Set("2m"); err != nil || v.String() != "2m" {
+		t.Errorf("Expected valid duration, got %q (%v)", v.String(), err)
+	}
+	if err := v.Set("soon"); err == nil {
+		t.Error("Expected invalid duration to be rejected")
+	}
+	v.Reset()
+	if v.String() != "1s" {
+		t.Errorf("Expected reset to default, got %q", v.String())
+	}
+	if !(&pluginFlagValue{typ: "bool"}).IsBoolFlag() {
+		t.Error("Expected bool plugin flag to be a bool flag")
+	}
+
+	list := &pluginFlagValue{typ: "int", repeated: true}
+	_ = list.Set("1")
+	_ = list.Set("2")
+	if !slices.Equal(list.values, []string{"1", "2"}) {
+		t.Errorf("Expected repeated values to accumulate, got %q", list.values)
+	}
+	clone := list.cloneFlag().(*pluginFlagValue)
+	if len(clone.values) != 0 || !clone.repeated {
+		t.Errorf("Expected clone in default state, got %+v", clone)
+	}
+}
+
+func TestFlagType(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("s", "", "")
+	fs.Bool("b", false, "")
+	fs.Int("i", 0, "")
+	fs.Uint64("u", 0, "")
+	fs.Float64("f", 0, "")
+	fs.Duration("d", 0, "")
+	fs.Func("fn", "", func(string) error { return nil })
+
+	want := map[string]string{"s": "string", "b": "bool", "i": "int", "u": "int", "f": "float", "d": "duration", "fn": "string"}
+	for name, typ := range want {
+		if got := flagType(fs.Lookup(name).Value); got != typ {
+			t.Errorf("flagType(-%s) = %q, want %q", name, got, typ)
+		}
+	}
+}
+
+func TestServePlugin_Protocol(t *testing.T) {
+	cmd := NewCommand("echo", "Echo")
+	cmd.Action = func(ctx context.Context, cmd *Command) error {
+		fmt.Fprint(cmd.Stdout(), strings.Join(cmd.Flags.Args(), " "))
+		return nil
+	}
+
+	in := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"nope"}
+{"jsonrpc":"2.0","method":"ignored"}
+{"jsonrpc":"2.0","id":2,"method":"run","params":{"command":"echo","args":["-a","b"]}}
+`)
+	out := &bytes.Buffer{}
+	if err := servePlugin(in, out, []*Command{cmd}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{
+		`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found: nope","data":{}}}`,
+		`{"jsonrpc":"2.0","method":"output","params":{"stream":"stdout","data":"LWEgYg=="}}`,
+		`{"jsonrpc":"2.0","id":2,"result":{"exitCode":0}}`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected protocol output:\n%s", out.String())
+	}
+}
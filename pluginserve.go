@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServePlugin 在插件进程中提供命令，供主程序通过 Program.LoadPlugin 加载
+//
+// 通过标准输入输出与主程序通信，直到标准输入关闭。命令的标志、用法和 Params 会自动描述给主程序；
+// 执行时 Action 收到的标志值和参数已由主程序解析，写到 cmd.Stdout()、cmd.Stderr() 的内容
+// 会实时转发给主程序。插件命令不能读取标准输入；启用了 Interactive 的命令缺失的必需值由主程序询问。
+//
+//	func main() {
+//		if err := cli.ServePlugin(greetCmd, deployCmd); err != nil {
+//			fmt.Fprintln(os.Stderr, err)
+//			os.Exit(1)
+//		}
+//	}
+func ServePlugin(commands ...*Command) error {
+	return servePlugin(os.Stdin, os.Stdout, commands)
+}
+
+// pluginServer 插件端的协议处理
+type pluginServer struct {
+	prog   *Program
+	stdout *rpcOutputWriter
+	stderr *rpcOutputWriter
+
+	mu  sync.Mutex // 保护 enc
+	enc *json.Encoder
+
+	cancelMu sync.Mutex
+	cancel   context.CancelFunc // 正在执行的命令
+}
+
+// servePlugin 从 r 读取请求并向 w 写出响应和通知
+func servePlugin(r io.Reader, w io.Writer, commands []*Command) error {
+	s := &pluginServer{
+		prog: &Program{Commands: commands, HideHelpCommand: true, HideVersionCommand: true},
+		enc:  json.NewEncoder(w),
+	}
+	s.stdout = &rpcOutputWriter{server: s, stream: "stdout"}
+	s.stderr = &rpcOutputWriter{server: s, stream: "stderr"}
+	s.prog.SetStdin(strings.NewReader(""))
+	s.prog.SetStdout(s.stdout)
+	s.prog.SetStderr(s.stderr)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	dec := json.NewDecoder(r)
+	for {
+		var msg rpcMessage
+		if err := dec.Decode(&msg); err != nil {
+			s.cancelRun()
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		switch {
+		case msg.Method == rpcCancel:
+			s.cancelRun()
+		case msg.ID == nil:
+			// 忽略未知的通知
+		case msg.Method == rpcDescribe:
+			s.reply(msg.ID, s.describe(), nil)
+		case msg.Method == rpcRun:
+			var params runParams
+			if err := json.Unmarshal(msg.Params, &params); err != nil {
+				s.reply(msg.ID, nil, &rpcError{Code: -32602, Message: err.Error()})
+				continue
+			}
+			// 在后台执行，以便继续接收 cancel 通知
+			ctx, cancel := context.WithCancel(context.Background())
+			s.cancelMu.Lock()
+			s.cancel = cancel
+			s.cancelMu.Unlock()
+			wg.Go(func() {
+				defer cancel()
+				s.run(ctx, msg.ID, params)
+			})
+		default:
+			s.reply(msg.ID, nil, &rpcError{Code: -32601, Message: "method not found: " + msg.Method})
+		}
+	}
+}
+
+// cancelRun 取消正在执行的命令
+func (s *pluginServer) cancelRun() {
+	s.cancelMu.Lock()
+	defer s.cancelMu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// describe 描述所有命令
+func (s *pluginServer) describe() pluginSpec {
+	var spec pluginSpec
+	for _, cmd := range s.prog.Commands {
+		cs := commandSpec{
+			Name:        cmd.Name,
+			Usage:       cmd.Usage,
+			Description: cmd.Description,
+			Interactive: cmd.Interactive,
+			HideOutput:  cmd.HideOutputFlag,
+		}
+		cmd.Flags.VisitAll(func(f *flag.Flag) {
+			fs := flagSpec{
+				Name:     f.Name,
+				Usage:    f.Usage,
+				Type:     flagType(f.Value),
+				Repeated: isRepeatedFlag(f.Value),
+			}
+			if !fs.Repeated {
+				// 切片的默认值（如 "[a b]"）无法按值还原，由插件自己保留
+				fs.Default = f.DefValue
+			}
+			cs.Flags = append(cs.Flags, fs)
+		})
+		for _, p := range cmd.Params {
+			ps := paramSpec{
+				Name:       p.Name,
+				Positional: p.Positional,
+				Required:   p.Required,
+				Prompt:     p.Prompt,
+				Default:    p.Default,
+				Options:    p.Options,
+			}
+			if int(p.Type) < len(promptTypeNames) {
+				ps.Type = promptTypeNames[p.Type]
+			}
+			cs.Params = append(cs.Params, ps)
+		}
+		spec.Commands = append(spec.Commands, cs)
+	}
+	return spec
+}
+
+// run 使用主程序解析好的标志和参数执行命令
+func (s *pluginServer) run(ctx context.Context, id *int64, params runParams) {
+	cmd := s.prog.get(params.Command)
+	if cmd == nil {
+		s.reply(id, nil, &rpcError{Code: -32602, Message: "unknown command: " + params.Command})
+		return
+	}
+
+	// 主程序内置标志的值交给插件端同样的内置标志（命令自己定义了同名标志时不传递）
+	var args []string
+	if params.Output != "" && !cmd.HideOutputFlag && cmd.Flags.Lookup("output") == nil {
+		args = append(args, "-output="+params.Output)
+	}
+	if params.Timeout != "" && cmd.Flags.Lookup("timeout") == nil {
+		args = append(args, "-timeout="+params.Timeout)
+	}
+	s.stdout.terminal = params.StdoutTerminal
+	s.stderr.terminal = params.StderrTerminal
+
+	names := make([]string, 0, len(params.Flags))
+	for name := range params.Flags {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, value := range params.Flags[name] {
+			args = append(args, fmt.Sprintf("-%s=%s", name, value))
+		}
+	}
+	args = append(append(args, "--"), params.Args...)
+
+	err := s.prog.execute(ctx, cmd, nil, args)
+	if err != nil {
+		rpcErr := &rpcError{Code: rpcErrorCommandFailed, Message: err.Error()}
+		rpcErr.Data.ExitCode = ExitCode(err)
+		s.reply(id, nil, rpcErr)
+		return
+	}
+	s.reply(id, runResult{}, nil)
+}
+
+// reply 写出响应
+func (s *pluginServer) reply(id *int64, result any, rpcErr *rpcError) {
+	msg := rpcMessage{ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			msg.Error = &rpcError{Code: -32603, Message: err.Error()}
+		} else {
+			msg.Result = raw
+		}
+	}
+	_ = s.send(msg)
+}
+
+// send 写出一条消息
+func (s *pluginServer) send(msg rpcMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg.JSONRPC = "2.0"
+	return s.enc.Encode(msg)
+}
+
+// rpcOutputWriter 将写入的内容作为 output 通知发送给主程序
+//
+// 实现 Terminal 接口，报告主程序对应的输出流是否为终端。
+type rpcOutputWriter struct {
+	server   *pluginServer
+	stream   string
+	terminal bool
+}
+
+// IsTerminal 实现 Terminal 接口
+func (w *rpcOutputWriter) IsTerminal() bool {
+	return w.terminal
+}
+
+// Write 实现 io.Writer 接口
+func (w *rpcOutputWriter) Write(p []byte) (int, error) {
+	raw, err := json.Marshal(outputParams{Stream: w.stream, Data: p})
+	if err != nil {
+		return 0, err
+	}
+	if err := w.server.send(rpcMessage{Method: rpcOutput, Params: raw}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// flagType 推断标志值在协议中的类型
+func flagType(v flag.Value) string {
+	if g, ok := v.(flag.Getter); ok {
+		switch g.Get().(type) {
+		case bool:
+			return "bool"
+		case int, int64, uint, uint64:
+			return "int"
+		case float64:
+			return "float"
+		case time.Duration:
+			return "duration"
+		}
+	}
+	if bf, ok := v.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+		return "bool"
+	}
+	return "string"
+}
+
+// isRepeatedFlag 判断标志是否可以重复出现（值的底层类型为切片，如自定义的列表标志）
+func isRepeatedFlag(v flag.Value) bool {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv.Kind() == reflect.Slice
+}
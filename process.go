@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+)
+
+// runProcess 使用程序的输入输出、环境变量和工作目录运行外部进程
+//
+// context 取消时向进程转发取消原因中的信号（默认 os.Interrupt），
+// 并在 ShutdownTimeout 后强制结束。进程因信号退出时返回 *SignalError，
+// 其它非零退出返回 *exec.ExitError，两者都能通过 ExitCode 得到正确的退出码。
+func (p *Program) runProcess(ctx context.Context, c *exec.Cmd) error {
+	if c.Env == nil {
+		c.Env = p.Environ()
+	}
+	if c.Dir == "" {
+		c.Dir = p.dir
+	}
+	c.Stdin = p.Stdin()
+	c.Stdout = p.Stdout()
+	c.Stderr = p.Stderr()
+	c.Cancel = func() error {
+		var sig os.Signal = os.Interrupt
+		var se *SignalError
+		if errors.As(context.Cause(ctx), &se) {
+			sig = se.Signal
+			// 终端上的 Ctrl+C 已经发送给整个前台进程组，不再重复发送
+			if sig == os.Interrupt && isTerminal(p.Stdin()) {
+				return nil
+			}
+		}
+		if err := c.Process.Signal(sig); err != nil {
+			return c.Process.Kill()
+		}
+		return nil
+	}
+	c.WaitDelay = p.shutdownTimeout()
+
+	err := c.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if sig := exitSignal(exitErr); sig != nil {
+			return &SignalError{Signal: sig}
+		}
+	}
+	return err
+}
+
+// cancelOnSignals 返回收到 SIGINT/SIGTERM 时以 *SignalError 取消的 context
+//
+// 在调用返回的 stop 之前，这些信号不会结束进程。
+func (p *Program) cancelOnSignals(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	sigCh := make(chan os.Signal, 1)
+	p.notify(sigCh, shutdownSignals...)
+	go func() {
+		select {
+		case sig := <-sigCh:
+			cancel(&SignalError{Signal: sig})
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sigCh)
+		cancel(nil)
+	}
+}
@@ -2,9 +2,11 @@ package cli
 
 import (
 	"context"
+	"crypto/ed25519"
 	"fmt"
 	"io"
 	"os"
+	"time"
 )
 
 // Program CLI 应用程序
@@ -21,7 +23,82 @@ type Program struct {
 	HideVersionFlag    bool       // 隐藏 -v/--version 标志
 	HelpCommand        *Command   // help 命令（可自定义）
 	VersionCommand     *Command   // version 命令（可自定义）
-	output             io.Writer  // 输出目标（测试时可替换，默认 os.Stderr）
+
+	// UseBuildInfo 启用后，版本信息包含从 runtime/debug.ReadBuildInfo 读取的 VCS 修订版本、
+	// 提交时间和修改标记，Version 为空时使用主模块的版本（见 BuildInfo）
+	UseBuildInfo    bool
+	VersionTemplate string // version 命令和 -v 标志输出的 text/template 模板（数据为 BuildInfo）
+
+	// HandleSignals 启用后，RunContext 会在收到 SIGINT/SIGTERM 时取消 context，
+	// 并在 ShutdownTimeout 内等待 Action 返回；再次收到信号时强制退出
+	HandleSignals   bool
+	ShutdownTimeout time.Duration // 收到信号后的宽限期（默认 DefaultShutdownTimeout）
+
+	Timeout time.Duration // 命令默认的最长执行时间（命令未设置 Timeout 时生效）
+
+	// RecoverPanics 启用后，Action 中的 panic 会被恢复并转换为 *PanicError，
+	// 同时打印友好提示并在 CrashReportDir 中写入崩溃报告
+	RecoverPanics  bool
+	CrashReportDir string   // 崩溃报告目录（默认 os.TempDir()）
+	SecretFlags    []string // 额外的敏感标志名称，其值在崩溃报告中会被隐藏
+
+	EnableShell      bool   // 启用内置 shell 命令（交互式命令行，见 Shell）
+	ShellPrompt      string // 交互式命令行提示符（默认 "<Name>> "）
+	ShellHistoryFile string // 历史记录文件（默认 <用户缓存目录>/<Name>/shell_history）
+
+	EnableScript bool // 启用内置 run-script 命令（批量执行脚本，见 RunScript）
+
+	// ResponseFiles 启用后，参数中的 @path 会被替换为从文件中读取的参数，
+	// 用于传递超出系统命令行长度限制的大量参数；@@ 开头的参数表示以 @ 开头的普通参数
+	ResponseFiles bool
+
+	// Aliases 命令别名（名称 → 展开后的命令行），以 ! 开头的值作为 shell 脚本执行。
+	// ConfigFile 中的 aliases.<名称> 会覆盖同名别名；与命令同名的别名会被忽略
+	Aliases    map[string]string
+	ConfigFile string // 配置文件路径（格式见 README，文件不存在时忽略）
+
+	// EnablePlugins 启用后，未知命令会交给 PATH 中名为 <Name>-<命令> 的可执行文件处理
+	EnablePlugins bool
+
+	// UpdateSource 设置后启用内置 update 命令，从中检查比 Version 新的发布版本并替换当前可执行文件
+	UpdateSource    ReleaseSource
+	UpdateChannel   string            // 默认的发布通道（默认 ChannelStable）
+	UpdatePublicKey ed25519.PublicKey // 设置后要求发布文件带有有效的 ed25519 签名
+
+	// UpdateChecker 设置后在后台检查新版本，并在命令结束后于标准错误提示（见 ReleaseChecker）。
+	// 标准错误不是终端、设置了 CI 或 <PREFIX>_NO_UPDATE_NOTIFIER 环境变量时不检查
+	UpdateChecker       UpdateChecker
+	UpdateCheckInterval time.Duration // 两次检查之间的最小间隔（默认 DefaultUpdateCheckInterval）
+
+	// Theme 设置后帮助和错误信息使用该样式（如 &DefaultTheme），并启用全局标志 --color=auto|always|never。
+	// auto 模式下输出为终端时才使用颜色，并遵循 NO_COLOR 和 FORCE_COLOR 环境变量
+	Theme *Theme
+
+	// EnableLogging 启用全局标志 -v（可重复）、-q、--log-level 和 --log-format，
+	// 按标志配置写入标准错误的 *slog.Logger，通过 Command.Logger 或 LoggerFrom(ctx) 获取。
+	// 启用后 -v 表示提高日志详细程度，版本号通过 --version 查看
+	EnableLogging bool
+
+	// EnablePager 启用后，标准输出为终端时帮助通过分页器显示（<PREFIX>_PAGER、PAGER，默认 DefaultPager），
+	// 并启用全局标志 --no-pager；设置 <PREFIX>_NO_PAGER 或将 <PREFIX>_PAGER 设为空或 cat 时不使用分页器
+	EnablePager bool
+
+	// EnableDryRun 启用全局标志 --dry-run：命令通过 Command.Would 或 DryRunFrom(ctx) 记录本应执行的副作用，
+	// 结束后在标准错误输出汇总；声明了 Command.NoDryRun 的命令拒绝以 --dry-run 执行
+	EnableDryRun bool
+
+	// Audit 设置后，每次调用结束时写入审计记录（时间、用户、主机、工作目录、命令、隐藏敏感值的参数、
+	// 执行时间、退出码和错误），如 &AuditFile{Path: ...}；交互式命令行和脚本中的每一行单独记录
+	Audit AuditSink
+
+	processEnv // 环境变量、工作目录、时钟、主机名和用户名（测试时可替换，默认使用真实的进程状态）
+
+	stdin         io.Reader                            // 标准输入（测试时可替换，默认 os.Stdin）
+	stdout        io.Writer                            // 标准输出（测试时可替换，默认 os.Stdout）
+	stderr        io.Writer                            // 标准错误（测试时可替换，默认 os.Stderr）
+	notifySignals func(chan<- os.Signal, ...os.Signal) // 信号注册函数（测试时可替换，默认 signal.Notify）
+	exitFunc      func(int)                            // 进程退出函数（测试时可替换，默认 os.Exit）
+	executable    func() (string, error)               // 可执行文件路径（测试时可替换，默认 os.Executable）
 }
 
 // NewProgram 创建 CLI 应用程序
@@ -32,31 +109,73 @@ func NewProgram(appName, version string) *Program {
 	}
 }
 
-// SetOutput 设置输出目标
+// SetOutput 设置输出目标（同时作为标准输出和标准错误）
 func (p *Program) SetOutput(w io.Writer) {
-	p.output = w
+	p.stdout = w
+	p.stderr = w
 }
 
-// Output 获取输出目标，如果未设置则返回 os.Stderr
+// Output 获取输出目标（即标准错误），如果未设置则返回 os.Stderr
 func (p *Program) Output() io.Writer {
-	if p.output == nil {
+	return p.Stderr()
+}
+
+// SetStdin 设置标准输入
+func (p *Program) SetStdin(r io.Reader) {
+	p.stdin = r
+}
+
+// Stdin 获取标准输入，如果未设置则返回 os.Stdin
+func (p *Program) Stdin() io.Reader {
+	if p.stdin == nil {
+		return os.Stdin
+	}
+	return p.stdin
+}
+
+// SetStdout 设置标准输出（命令输出、显式请求的帮助和版本信息）
+func (p *Program) SetStdout(w io.Writer) {
+	p.stdout = w
+}
+
+// Stdout 获取标准输出，如果未设置则返回 os.Stdout
+func (p *Program) Stdout() io.Writer {
+	if p.stdout == nil {
+		return os.Stdout
+	}
+	return p.stdout
+}
+
+// SetStderr 设置标准错误（错误信息、诊断信息）
+func (p *Program) SetStderr(w io.Writer) {
+	p.stderr = w
+}
+
+// Stderr 获取标准错误，如果未设置则返回 os.Stderr
+func (p *Program) Stderr() io.Writer {
+	if p.stderr == nil {
 		return os.Stderr
 	}
-	return p.output
+	return p.stderr
 }
 
-// Get 获取命令并配置其输出和应用名称
+// Get 获取命令并配置其输入输出和应用名称
 //
 // 从已注册的命令和内置命令（help、version）中查找指定名称的命令。
-// 找到命令后会自动设置命令的输出目标和应用名称。
+// 返回的是本次调用专用的命令副本：已设置输入输出流、应用名称和默认超时时间，
+// 标志值已重置为默认值，原始命令不会被修改。
 func (p *Program) Get(name string) *Command {
-	if cmd := p.get(name); cmd != nil {
-		cmd.SetOutput(p.Output())
-		cmd.SetAppName(p.Name)
-		return cmd
+	cmd := p.get(name)
+	if cmd == nil {
+		return nil
 	}
 
-	return nil
+	var inv *Command
+	_ = p.invoke(cmd, func(c *Command) error {
+		inv = c
+		return nil
+	})
+	return inv
 }
 
 func (p *Program) get(name string) *Command {
@@ -83,15 +202,56 @@ func (p *Program) get(name string) *Command {
 			return p.VersionCommand
 		}
 		// 临时创建默认命令
-		return DefaultVersionCommand()
+		return p.versionCommand()
+	}
+
+	if p.EnableShell && name == "shell" {
+		return p.shellCommand()
+	}
+
+	if p.EnableScript && name == "run-script" {
+		return p.scriptCommand()
+	}
+
+	if p.UpdateSource != nil && name == "update" {
+		return p.updateCommand()
 	}
 
 	return nil
 }
 
-// PrintUsage 打印总体使用帮助到指定输出
+// PrintUsage 打印总体使用帮助到标准输出（启用 EnablePager 时通过分页器显示）
 func (p *Program) PrintUsage() error {
-	w := p.Output()
+	return p.printHelp(nil, "")
+}
+
+// PrintError 在标准错误输出 "Error: <err>"（使用 Theme 的错误样式）
+func (p *Program) PrintError(err error) {
+	p.printError("", "Error: %v", err)
+}
+
+// printError 在标准错误输出一行错误信息，mode 为颜色模式
+func (p *Program) printError(mode, format string, args ...any) {
+	st := p.styles(p.Theme, p.Stderr(), mode)
+	_, _ = fmt.Fprintln(p.Stderr(), st.Error.Render(fmt.Sprintf(format, args...)))
+}
+
+// printWarning 在标准错误输出一行警告信息
+func (p *Program) printWarning(format string, args ...any) {
+	st := p.styles(p.Theme, p.Stderr(), "")
+	_, _ = fmt.Fprintln(p.Stderr(), st.Warning.Render(fmt.Sprintf(format, args...)))
+}
+
+// printHelp 打印总体使用帮助到标准输出（启用 EnablePager 时通过分页器显示），mode 为颜色模式
+func (p *Program) printHelp(args []string, mode string) error {
+	return p.pageHelp(args, func(w io.Writer) error {
+		return p.printUsage(w, mode)
+	})
+}
+
+// printUsage 打印总体使用帮助到 w，mode 为颜色模式
+func (p *Program) printUsage(w io.Writer, mode string) error {
+	st := p.styles(p.Theme, w, mode)
 	var b []byte
 
 	// 如果有横幅，先打印横幅
@@ -100,16 +260,16 @@ func (p *Program) PrintUsage() error {
 		b = fmt.Appendln(b) // 空行分隔
 	}
 
-	b = fmt.Appendf(b, "%s version %s\n", p.Name, p.Version)
+	b = fmt.Appendf(b, "%s version %s\n", p.Name, p.BuildInfo().Version)
 
 	// 如果有应用描述，打印它
 	if p.Usage != "" {
 		b = fmt.Appendf(b, "%s\n", p.Usage)
 	}
 
-	b = fmt.Appendf(b, "\nUSAGE:\n")
+	b = fmt.Appendf(b, "\n%s\n", st.Heading.Render("USAGE:"))
 	b = fmt.Appendf(b, "    %s [command] [options]\n\n", p.Name)
-	b = fmt.Appendf(b, "COMMANDS:\n")
+	b = fmt.Appendf(b, "%s\n", st.Heading.Render("COMMANDS:"))
 
 	// 计算最长命令名长度，用于对齐
 	maxLen := 0
@@ -121,9 +281,13 @@ func (p *Program) PrintUsage() error {
 
 	// 按注册顺序打印命令
 	for _, cmd := range p.Commands {
-		b = fmt.Appendf(b, "    %-*s    %s\n", maxLen, cmd.Name, cmd.Usage)
+		b = fmt.Appendf(b, "    %s    %s\n", st.Command.Render(fmt.Sprintf("%-*s", maxLen, cmd.Name)), cmd.Usage)
 	}
 
+	b = p.appendAliasUsage(b, st)
+	b = p.appendPluginUsage(b, st)
+	b = p.appendGlobalUsage(b, st)
+
 	b = fmt.Appendf(b, "\nRun '%s [command] -h' for more information on a command.\n", p.Name)
 
 	// 一次性写入到 w
@@ -142,92 +306,200 @@ func isFlag(arg string) bool {
 }
 
 // RunContext 使用指定的 context 运行命令
+//
+// 启用 HandleSignals 时，传给 Action 的 context 派生自 ctx，
+// 并会在收到 SIGINT/SIGTERM 时以 *SignalError 为原因取消。
 func (p *Program) RunContext(ctx context.Context, args []string) error {
+	notice := p.startUpdateCheck(ctx, args)
+	defer notice()
+
+	return p.runAudited(ctx, args, func(ctx context.Context, args []string) error {
+		if p.HandleSignals {
+			return p.runWithSignals(ctx, args)
+		}
+		return p.run(ctx, args)
+	})
+}
+
+// run 解析参数并路由到对应命令
+func (p *Program) run(ctx context.Context, args []string) error {
+	if p.ResponseFiles && len(args) > 1 {
+		expanded, err := expandResponseFiles(args[1:], p.Dir())
+		if err != nil {
+			return err
+		}
+		args = append([]string{args[0]}, expanded...)
+	}
+
+	// 确定命令之前输出的帮助和错误信息使用的颜色模式，以及是否指定了 --no-pager
+	var colorMode string
+	var flagArgs []string
+	if len(args) > 1 {
+		flagArgs = args[1:]
+	}
+	if p.Theme != nil {
+		colorMode = colorModeArg(flagArgs)
+	}
+
+	// 分离出现在命令名称之前的全局标志，确定命令后再交给命令参数一并处理
+	routed := args
+	var lead []string
+	if len(args) > 1 {
+		var rest []string
+		lead, rest = p.leadingGlobalFlags(args[1:])
+		routed = append([]string{args[0]}, rest...)
+	}
+
 	// 解析命令名称和参数起始位置
 	var cmdName string
 	var cmdArgs []string
 	var usingDefaultCommand bool
 
-	if len(args) < 2 || isFlag(args[1]) {
+	if len(routed) < 2 || isFlag(routed[1]) {
 		// 没有提供命令，或第一个参数是 flag
 		if p.DefaultCommand != "" {
 			cmdName = p.DefaultCommand
 			usingDefaultCommand = true
-			if len(args) >= 2 {
-				cmdArgs = args[1:] // 将 flag 传递给默认命令
+			if len(routed) >= 2 {
+				cmdArgs = routed[1:] // 将 flag 传递给默认命令
 			} else {
 				cmdArgs = []string{}
 			}
 		} else {
 			// 没有默认命令，显示帮助
-			return p.PrintUsage()
+			return p.printHelp(flagArgs, colorMode)
 		}
 	} else {
 		// 显式指定了命令
-		cmdName = args[1]
-		cmdArgs = args[2:]
+		cmdName = routed[1]
+		cmdArgs = routed[2:]
+	}
+
+	// 展开别名（别名不会遮蔽真正的命令）
+	alias := cmdName
+	cmdName, cmdArgs, script, err := p.expandAlias(cmdName, cmdArgs)
+	if err != nil {
+		return err
+	}
+	if script != "" {
+		auditTrailFrom(ctx).setCommand(alias, cmdArgs)
+		return p.runShellAlias(ctx, alias, script, cmdArgs)
+	}
+	if len(lead) > 0 {
+		cmdArgs = append(lead, cmdArgs...)
+	}
+	auditTrailFrom(ctx).setCommand(cmdName, cmdArgs)
+
+	// 交给插件处理（插件自行处理帮助等参数）
+	if p.get(cmdName) == nil {
+		if path := p.findPlugin(cmdName); path != "" {
+			return p.runPlugin(ctx, path, cmdArgs)
+		}
 	}
 
 	// 处理全局 flag（检查 cmdArgs 中是否包含全局 flag）
 	for _, arg := range cmdArgs {
-		if !p.HideVersionFlag && (arg == "-v" || arg == "--version") {
-			if _, err := fmt.Fprintf(p.Output(), "%s version %s\n", p.Name, p.Version); err != nil {
-				return err
-			}
-			return nil
+		if !p.HideVersionFlag && (arg == "--version" || (arg == "-v" && !p.EnableLogging)) {
+			return p.writeVersion(p.Stdout())
 		}
 		if !p.HideHelpFlag && (arg == "-h" || arg == "--help") {
-			return p.PrintUsage()
+			return p.printHelp(flagArgs, colorMode)
 		}
 	}
 
 	// 处理特殊命令
-	// 1. 处理 version 命令
-	if !p.HideVersionCommand && cmdName == "version" {
-		if _, err := fmt.Fprintf(p.Output(), "%s version %s\n", p.Name, p.Version); err != nil {
-			return err
-		}
-		return nil
+	// 1. 处理没有 Action 的自定义 version 命令（默认命令作为普通命令执行，以便解析 --json 等标志）
+	if !p.HideVersionCommand && cmdName == "version" && p.VersionCommand != nil && p.VersionCommand.Action == nil {
+		return p.writeVersion(p.Stdout())
 	}
 
 	// 2. 处理 help 命令：help [command]
 	if !p.HideHelpCommand && cmdName == "help" {
-		if len(cmdArgs) > 0 {
+		// 出现在 help 之前的全局标志不是命令名称
+		if helpArgs := cmdArgs[len(lead):]; len(helpArgs) > 0 {
 			// help [command] - 显示特定命令的帮助
-			subCmdName := cmdArgs[0]
-			cmd := p.Get(subCmdName)
+			subCmdName := helpArgs[0]
+			cmd := p.get(subCmdName)
+			if path := p.findPlugin(subCmdName); cmd == nil && path != "" {
+				return p.runPlugin(ctx, path, []string{"--help"})
+			}
 			if cmd == nil {
-				if _, err := fmt.Fprintf(p.Output(), "help: unknown command: %s\n", subCmdName); err != nil {
-					return err
-				}
+				p.printError(colorMode, "help: unknown command: %s", subCmdName)
 				return fmt.Errorf("unknown command: %s", subCmdName)
 			}
-			return cmd.PrintUsage()
+			return p.invoke(cmd, func(inv *Command) error {
+				inv.colorMode = colorMode
+				return p.pageHelp(flagArgs, inv.printUsage)
+			})
 		}
 		// help - 显示总体帮助
-		return p.PrintUsage()
+		return p.printHelp(flagArgs, colorMode)
 	}
 
 	// 查找并执行命令
-	cmd := p.Get(cmdName)
+	cmd := p.get(cmdName)
 	if cmd == nil {
 		if usingDefaultCommand {
-			if _, err := fmt.Fprintf(p.Output(), "Default command '%s' not found\n\n", cmdName); err != nil {
-				return err
-			}
-			if err := p.PrintUsage(); err != nil {
+			p.printError(colorMode, "Default command '%s' not found", cmdName)
+			_, _ = fmt.Fprintln(p.Stderr())
+			if err := p.printUsage(p.Stderr(), colorMode); err != nil {
 				return err
 			}
 			return fmt.Errorf("default command not found: %s", cmdName)
 		}
-		if _, err := fmt.Fprintf(p.Output(), "Unknown command: %s\n\n", cmdName); err != nil {
-			return err
-		}
-		if err := p.PrintUsage(); err != nil {
+		p.printError(colorMode, "Unknown command: %s", cmdName)
+		_, _ = fmt.Fprintln(p.Stderr())
+		if err := p.printUsage(p.Stderr(), colorMode); err != nil {
 			return err
 		}
 		return fmt.Errorf("unknown command: %s", cmdName)
 	}
 
-	return cmd.RunContext(ctx, cmdArgs)
+	return p.execute(ctx, cmd, args, cmdArgs)
+}
+
+// execute 使用独立的解析状态执行命令，启用 RecoverPanics 时恢复其中的 panic
+func (p *Program) execute(ctx context.Context, cmd *Command, args, cmdArgs []string) error {
+	return p.invoke(cmd, func(inv *Command) (err error) {
+		if p.RecoverPanics {
+			defer p.recoverPanic(inv, args, &err)
+		}
+		rest, err := p.applyGlobalFlags(inv, cmdArgs)
+		if err != nil {
+			return err
+		}
+		// 执行前先记录一次，强制退出时写入的审计记录也能按标志定义脱敏；结束后再记录确认状态
+		trail := auditTrailFrom(ctx)
+		trail.setInvocation(inv)
+		defer trail.setInvocation(inv)
+		if p.EnableLogging {
+			ctx = context.WithValue(ctx, loggerKey{}, inv.setupLogger())
+		}
+		if inv.dryRun != nil {
+			return inv.runDryRun(ctx, func(ctx context.Context) error {
+				return p.runInvocation(ctx, inv, rest)
+			})
+		}
+		return p.runInvocation(ctx, inv, rest)
+	})
+}
+
+// runInvocation 解析参数并执行命令副本，命令启用 Pager 时输出通过分页器显示
+//
+// 分页器会接管终端，因此先在分页器外完成参数解析、交互式询问和危险命令的确认，
+// 只有 Action 的输出通过分页器显示。
+func (p *Program) runInvocation(ctx context.Context, inv *Command, args []string) error {
+	if !inv.Pager || inv.noPager {
+		return inv.RunContext(ctx, args)
+	}
+	if ok, err := inv.prepare(args); !ok {
+		return err
+	}
+	if inv.Action == nil {
+		return nil
+	}
+	return p.withPager(func(w io.Writer) error {
+		inv.SetStdout(w)
+		return inv.runAction(ctx)
+	})
 }
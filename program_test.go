@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"os"
 	"strings"
 	"testing"
 )
@@ -818,3 +821,88 @@ func TestProgram_DefaultCommand_WithExplicitCommandAndFlags(t *testing.T) {
 		t.Error("Expected web port NOT to be set")
 	}
 }
+
+func TestProgram_StdioDefaults(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+
+	if prog.Stdin() != os.Stdin {
+		t.Error("Expected Stdin() to default to os.Stdin")
+	}
+	if prog.Stdout() != os.Stdout {
+		t.Error("Expected Stdout() to default to os.Stdout")
+	}
+	if prog.Stderr() != os.Stderr {
+		t.Error("Expected Stderr() to default to os.Stderr")
+	}
+}
+
+func TestProgram_StdioSeparateStreams(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	prog.SetStdout(stdout)
+	prog.SetStderr(stderr)
+
+	// 显式请求的帮助输出到标准输出
+	if err := prog.Run([]string{"testapp", "help"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(stdout.String(), "USAGE:") {
+		t.Error("Expected requested help on stdout")
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Expected nothing on stderr, got: %s", stderr.String())
+	}
+
+	// 版本信息输出到标准输出
+	stdout.Reset()
+	if err := prog.Run([]string{"testapp", "--version"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(stdout.String(), "testapp version 1.0.0") {
+		t.Error("Expected version on stdout")
+	}
+
+	// 错误信息和随附的帮助输出到标准错误
+	stdout.Reset()
+	if err := prog.Run([]string{"testapp", "nope"}); err == nil {
+		t.Fatal("Expected error for unknown command")
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("Expected nothing on stdout, got: %s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Unknown command: nope") || !strings.Contains(stderr.String(), "USAGE:") {
+		t.Errorf("Expected error and usage on stderr, got: %s", stderr.String())
+	}
+}
+
+func TestProgram_StdioInjectedIntoAction(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	prog.SetStdin(strings.NewReader("hello"))
+	prog.SetStdout(stdout)
+	prog.SetStderr(stderr)
+
+	echoCmd := NewCommand("echo", "Echo stdin")
+	echoCmd.Action = func(ctx context.Context, cmd *Command) error {
+		data, err := io.ReadAll(cmd.Stdin())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.Stdout(), "out: %s\n", data)
+		fmt.Fprintln(cmd.Stderr(), "done")
+		return nil
+	}
+	prog.Commands = []*Command{echoCmd}
+
+	if err := prog.Run([]string{"testapp", "echo"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stdout.String() != "out: hello\n" {
+		t.Errorf("Expected action output on stdout, got: %q", stdout.String())
+	}
+	if stderr.String() != "done\n" {
+		t.Errorf("Expected diagnostics on stderr, got: %q", stderr.String())
+	}
+}
@@ -0,0 +1,392 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	progressRedrawInterval = 100 * time.Millisecond // 终端上的重绘间隔
+	progressLogInterval    = 5 * time.Second        // 非终端时输出进度日志的间隔
+	progressBarWidth       = 30                     // 进度条的宽度（字符数）
+)
+
+// spinnerFrames 不确定进度的动画帧
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Progress 进度条或 spinner（总量未知时）
+//
+// 方法可以在多个 goroutine 中并发调用。Done 之后，或者创建时的 context 取消后，
+// 进度不再更新。
+type Progress struct {
+	group   *ProgressGroup
+	label   string
+	total   int64
+	current int64
+	start   time.Time
+	end     time.Time
+	state   progressState
+}
+
+// progressState 进度的状态
+type progressState int
+
+const (
+	progressRunning progressState = iota
+	progressDone
+	progressCanceled
+)
+
+// ProgressGroup 同时显示的一组进度条
+//
+// 终端上每个进度条占一行并原地重绘；否则定期输出一行进度日志，每个进度条完成时输出一行结果。
+// 调用 Stop 或 context 取消时停止显示；所有进度条完成后暂停刷新，之后仍可以继续 Add。
+type ProgressGroup struct {
+	ctx      context.Context
+	w        io.Writer
+	tty      bool             // 是否在终端上原地重绘
+	now      func() time.Time // 时钟（Command.Now）
+	redraw   time.Duration    // 终端上的重绘间隔
+	logEvery time.Duration    // 非终端时输出进度日志的间隔
+
+	mu      sync.Mutex
+	bars    []*Progress
+	lines   int  // 终端上已绘制的行数
+	frame   int  // spinner 动画帧
+	running bool // 刷新 goroutine 是否在运行
+	stopped bool
+	stop    chan struct{} // Stop 或 context 取消时关闭
+	idle    chan struct{} // 所有进度条完成时通知刷新 goroutine 退出
+	done    chan struct{} // 当前的刷新 goroutine 退出时关闭
+}
+
+// NewProgress 创建写入标准错误的进度条，total <= 0 时为 spinner
+func (c *Command) NewProgress(ctx context.Context, label string, total int64) *Progress {
+	return c.NewProgressGroup(ctx).Add(label, total)
+}
+
+// NewSpinner 创建写入标准错误的 spinner（不确定进度）
+func (c *Command) NewSpinner(ctx context.Context, label string) *Progress {
+	return c.NewProgress(ctx, label, 0)
+}
+
+// NewProgressGroup 创建写入标准错误的进度条组
+func (c *Command) NewProgressGroup(ctx context.Context) *ProgressGroup {
+	w := c.Stderr()
+	return &ProgressGroup{
+		ctx:      ctx,
+		w:        w,
+		tty:      isTerminal(w),
+		now:      c.Now,
+		redraw:   progressRedrawInterval,
+		logEvery: progressLogInterval,
+		stop:     make(chan struct{}),
+		idle:     make(chan struct{}, 1),
+	}
+}
+
+// Add 添加一个进度条，total <= 0 时为 spinner
+func (g *ProgressGroup) Add(label string, total int64) *Progress {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// 刷新暂停期间 context 可能已经取消
+	if !g.stopped && g.ctx.Err() != nil {
+		g.stopLocked(progressCanceled)
+	}
+	bar := &Progress{group: g, label: label, total: max(total, 0), start: g.now()}
+	if g.stopped {
+		bar.state = progressCanceled
+		return bar
+	}
+	g.bars = append(g.bars, bar)
+	if !g.running {
+		g.running = true
+		g.done = make(chan struct{})
+		go g.loop(g.done)
+	}
+	g.draw()
+	return bar
+}
+
+// Stop 停止显示，未完成的进度条视为取消
+func (g *ProgressGroup) Stop() {
+	g.finish(progressCanceled)
+}
+
+// loop 定期刷新，直到停止、context 取消或所有进度条完成
+func (g *ProgressGroup) loop(done chan struct{}) {
+	defer close(done)
+
+	interval := g.logEvery
+	if g.tty {
+		interval = g.redraw
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			g.mu.Lock()
+			g.frame++
+			if g.tty {
+				g.draw()
+			} else {
+				g.logRunning()
+			}
+			g.mu.Unlock()
+		case <-g.idle:
+			g.mu.Lock()
+			if !g.hasRunning() {
+				g.running = false
+				g.mu.Unlock()
+				return
+			}
+			g.mu.Unlock()
+		case <-g.ctx.Done():
+			g.stopDisplay(progressCanceled)
+			return
+		case <-g.stop:
+			return
+		}
+	}
+}
+
+// hasRunning 判断是否有未完成的进度条（调用方持有锁）
+func (g *ProgressGroup) hasRunning() bool {
+	for _, bar := range g.bars {
+		if bar.state == progressRunning {
+			return true
+		}
+	}
+	return false
+}
+
+// finish 停止显示并等待刷新 goroutine 退出，未完成的进度条标记为 state
+func (g *ProgressGroup) finish(state progressState) {
+	if done := g.stopDisplay(state); done != nil {
+		<-done
+	}
+}
+
+// stopDisplay 停止显示并输出最终状态，返回需要等待退出的刷新 goroutine（没有时为 nil）
+func (g *ProgressGroup) stopDisplay(state progressState) chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.stopLocked(state)
+}
+
+// stopLocked 停止显示并输出最终状态（调用方持有锁）
+func (g *ProgressGroup) stopLocked(state progressState) chan struct{} {
+	if g.stopped {
+		return nil
+	}
+	g.stopped = true
+	now := g.now()
+	for _, bar := range g.bars {
+		if bar.state == progressRunning {
+			bar.state, bar.end = state, now
+			if !g.tty {
+				g.writeLine(bar.status(now))
+			}
+		}
+	}
+	if g.tty {
+		g.draw()
+	}
+	close(g.stop)
+	if !g.running {
+		return nil
+	}
+	return g.done
+}
+
+// update 在锁的保护下修改进度，完成时输出结果
+func (p *Progress) update(fn func()) {
+	g := p.group
+	g.mu.Lock()
+	if p.state != progressRunning || g.stopped {
+		g.mu.Unlock()
+		return
+	}
+	fn()
+
+	if p.state == progressDone {
+		if !g.tty {
+			g.writeLine(p.status(p.end))
+		}
+		// 所有进度条都完成时让刷新 goroutine 退出，之后 Add 会重新启动
+		select {
+		case g.idle <- struct{}{}:
+		default:
+		}
+	}
+	g.draw()
+	g.mu.Unlock()
+}
+
+// Add 增加已完成的量（n 可以为负数，已完成的量最少为 0）
+func (p *Progress) Add(n int64) {
+	p.update(func() { p.current = max(p.current+n, 0) })
+}
+
+// Set 设置已完成的量，负数视为 0
+func (p *Progress) Set(n int64) {
+	p.update(func() { p.current = max(n, 0) })
+}
+
+// Write 实现 io.Writer 接口，按写入的字节数增加进度（可配合 io.TeeReader、io.MultiWriter）
+func (p *Progress) Write(b []byte) (int, error) {
+	p.Add(int64(len(b)))
+	return len(b), nil
+}
+
+// Done 标记完成
+func (p *Progress) Done() {
+	p.update(func() {
+		p.state, p.end = progressDone, p.group.now()
+		if p.total > 0 {
+			p.current = max(p.current, p.total)
+		}
+	})
+}
+
+// draw 在终端上重绘所有进度条（调用方持有锁）
+func (g *ProgressGroup) draw() {
+	if !g.tty {
+		return
+	}
+	var b strings.Builder
+	if g.lines > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", g.lines)
+	}
+	now := g.now()
+	for _, bar := range g.bars {
+		b.WriteString("\r\x1b[2K")
+		b.WriteString(bar.render(now, g.frame))
+		b.WriteString("\n")
+	}
+	g.lines = len(g.bars)
+	_, _ = io.WriteString(g.w, b.String())
+}
+
+// logRunning 输出所有未完成进度条的进度日志（调用方持有锁）
+func (g *ProgressGroup) logRunning() {
+	now := g.now()
+	for _, bar := range g.bars {
+		if bar.state == progressRunning {
+			g.writeLine(bar.status(now))
+		}
+	}
+}
+
+// writeLine 输出一行（调用方持有锁）
+func (g *ProgressGroup) writeLine(line string) {
+	_, _ = io.WriteString(g.w, line+"\n")
+}
+
+// elapsed 返回已用时间
+func (p *Progress) elapsed(now time.Time) time.Duration {
+	if p.state != progressRunning {
+		now = p.end
+	}
+	return max(now.Sub(p.start), 0)
+}
+
+// rate 返回每秒完成的量和预计剩余时间（无法估计时为负数）
+func (p *Progress) rate(now time.Time) (float64, time.Duration) {
+	elapsed := p.elapsed(now)
+	if elapsed <= 0 || p.current <= 0 {
+		return 0, -1
+	}
+	rate := float64(p.current) / elapsed.Seconds()
+	if p.total <= 0 || p.current >= p.total {
+		return rate, -1
+	}
+	return rate, time.Duration(float64(p.total-p.current) / rate * float64(time.Second))
+}
+
+// render 返回终端上显示的一行
+func (p *Progress) render(now time.Time, frame int) string {
+	if p.total <= 0 {
+		mark := spinnerFrames[frame%len(spinnerFrames)]
+		switch p.state {
+		case progressDone:
+			mark = "✓"
+		case progressCanceled:
+			mark = "✗"
+		}
+		line := mark + " " + p.label
+		if p.current > 0 {
+			line += fmt.Sprintf(" %d", p.current)
+		}
+		return line + " (" + formatETA(p.elapsed(now)) + ")"
+	}
+
+	filled := int(min(p.current, p.total) * progressBarWidth / p.total)
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+	line := fmt.Sprintf("%s [%s] %3d%% %d/%d", p.label, bar, p.percent(), p.current, p.total)
+	return line + p.suffix(now)
+}
+
+// status 返回非终端时输出的一行进度日志
+func (p *Progress) status(now time.Time) string {
+	var state string
+	switch p.state {
+	case progressDone:
+		state = "done"
+	case progressCanceled:
+		state = "canceled"
+	}
+
+	if p.total <= 0 {
+		if state == "" {
+			state = "running"
+		}
+		detail := formatETA(p.elapsed(now))
+		if p.current > 0 {
+			detail = fmt.Sprintf("%d, %s", p.current, detail)
+		}
+		return fmt.Sprintf("%s: %s (%s)", p.label, state, detail)
+	}
+
+	line := fmt.Sprintf("%s: %d%% (%d/%d)", p.label, p.percent(), p.current, p.total)
+	if state != "" {
+		line = fmt.Sprintf("%s: %s at %d%% (%d/%d)", p.label, state, p.percent(), p.current, p.total)
+	}
+	return line + p.suffix(now)
+}
+
+// percent 返回完成的百分比
+func (p *Progress) percent() int64 {
+	return min(p.current, p.total) * 100 / p.total
+}
+
+// suffix 返回速率、预计剩余时间或总耗时
+func (p *Progress) suffix(now time.Time) string {
+	if p.state != progressRunning {
+		return " in " + formatETA(p.elapsed(now))
+	}
+	rate, eta := p.rate(now)
+	s := ""
+	if rate > 0 {
+		s += fmt.Sprintf(" %.1f/s", rate)
+	}
+	if eta >= 0 {
+		s += " ETA " + formatETA(eta)
+	}
+	return s
+}
+
+// formatETA 以秒为精度格式化时间
+func formatETA(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testClock 可以手动拨动的时钟
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newProgressCommand 创建标准错误写入 out 的命令，tty 为 true 时模拟终端
+func newProgressCommand(tty bool) (*Command, *syncBuffer, *testClock) {
+	out := &syncBuffer{}
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cmd := NewCommand("test", "Test")
+	if tty {
+		cmd.SetStderr(ttyWriter{out})
+	} else {
+		cmd.SetStderr(out)
+	}
+	cmd.SetClock(clock.Now)
+	return cmd, out, clock
+}
+
+// waitForOutput 等待输出中出现 want
+func waitForOutput(t *testing.T, out *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %q, got %q", want, out.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestProgress_Log(t *testing.T) {
+	cmd, out, clock := newProgressCommand(false)
+	g := cmd.NewProgressGroup(t.Context())
+	g.logEvery = 10 * time.Millisecond
+
+	bar := g.Add("copy", 100)
+	clock.Advance(2 * time.Second)
+	bar.Add(50)
+	waitForOutput(t, out, "copy: 50% (50/100) 25.0/s ETA 2s\n")
+
+	clock.Advance(time.Second)
+	bar.Done()
+	if !strings.HasSuffix(out.String(), "copy: done at 100% (100/100) in 3s\n") {
+		t.Errorf("Expected final log line, got %q", out.String())
+	}
+
+	n := len(out.String())
+	time.Sleep(30 * time.Millisecond)
+	bar.Add(1)
+	if len(out.String()) != n {
+		t.Errorf("Expected no output after Done, got %q", out.String()[n:])
+	}
+}
+
+func TestProgress_Terminal(t *testing.T) {
+	cmd, out, clock := newProgressCommand(true)
+	bar := cmd.NewProgress(t.Context(), "copy", 100)
+	clock.Advance(2 * time.Second)
+	bar.Set(50)
+
+	want := "\r\x1b[2Kcopy [===============>              ]  50% 50/100 25.0/s ETA 2s\n"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("Expected redrawn bar %q, got %q", want, out.String())
+	}
+	if !strings.Contains(out.String(), "\x1b[1A") {
+		t.Errorf("Expected cursor to move up for redraw, got %q", out.String())
+	}
+
+	bar.Done()
+	if !strings.HasSuffix(out.String(), "copy [==============================] 100% 100/100 in 2s\n") {
+		t.Errorf("Expected final bar, got %q", out.String())
+	}
+}
+
+func TestProgress_NegativeCurrent(t *testing.T) {
+	cmd, out, _ := newProgressCommand(true)
+	bar := cmd.NewProgress(t.Context(), "copy", 100)
+
+	bar.Set(-5)
+	if !strings.HasSuffix(out.String(), "copy [>                             ]   0% 0/100\n") {
+		t.Errorf("Expected negative Set to render as 0, got %q", out.String())
+	}
+	bar.Add(10)
+	bar.Add(-20)
+	if !strings.HasSuffix(out.String(), "copy [>                             ]   0% 0/100\n") {
+		t.Errorf("Expected negative Add to stop at 0, got %q", out.String())
+	}
+	bar.Done()
+}
+
+func TestProgress_Group(t *testing.T) {
+	cmd, out, clock := newProgressCommand(true)
+	g := cmd.NewProgressGroup(t.Context())
+	a := g.Add("a", 10)
+	b := g.Add("b", 0)
+	clock.Advance(time.Second)
+
+	a.Done()
+	if !strings.Contains(out.String(), "\x1b[2A") {
+		t.Errorf("Expected both lines to be redrawn, got %q", out.String())
+	}
+	n := len(out.String())
+	b.Add(3)
+	b.Done()
+	last := out.String()[n:]
+	if !strings.HasSuffix(last, "\r\x1b[2Ka [==============================] 100% 10/10 in 1s\n\r\x1b[2K✓ b 3 (1s)\n") {
+		t.Errorf("Expected final group render, got %q", last)
+	}
+}
+
+func TestProgress_AddAfterDone(t *testing.T) {
+	cmd, out, _ := newProgressCommand(false)
+	g := cmd.NewProgressGroup(t.Context())
+	defer g.Stop()
+
+	g.Add("a", 1).Done()
+	b := g.Add("b", 10)
+	if b.state != progressRunning {
+		t.Fatal("Expected bar added after the first bar's Done to be running")
+	}
+	b.Add(5)
+	b.Done()
+	if !strings.HasSuffix(out.String(), "b: done at 100% (10/10) in 0s\n") {
+		t.Errorf("Expected second bar to report progress, got %q", out.String())
+	}
+}
+
+func TestProgress_IdleLoopExits(t *testing.T) {
+	cmd, _, _ := newProgressCommand(true)
+	g := cmd.NewProgressGroup(t.Context())
+	g.Add("a", 1).Done()
+
+	g.mu.Lock()
+	done := g.done
+	g.mu.Unlock()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected refresh goroutine to exit when all bars are done")
+	}
+
+	// 之后添加的进度条重新启动刷新
+	bar := g.Add("b", 1)
+	g.mu.Lock()
+	running := g.running
+	g.mu.Unlock()
+	if !running || bar.state != progressRunning {
+		t.Error("Expected refresh to restart for a new bar")
+	}
+	g.Stop()
+	if bar.state != progressCanceled {
+		t.Error("Expected Stop to cancel unfinished bars")
+	}
+}
+
+func TestProgress_Spinner(t *testing.T) {
+	cmd, out, clock := newProgressCommand(false)
+	spin := cmd.NewSpinner(t.Context(), "wait")
+	clock.Advance(3 * time.Second)
+	spin.Done()
+	if out.String() != "wait: done (3s)\n" {
+		t.Errorf("Unexpected spinner output %q", out.String())
+	}
+}
+
+func TestProgress_ContextCanceled(t *testing.T) {
+	cmd, out, _ := newProgressCommand(false)
+	ctx, cancel := context.WithCancel(t.Context())
+	bar := cmd.NewProgress(ctx, "copy", 100)
+	bar.Add(50)
+
+	cancel()
+	waitForOutput(t, out, "copy: canceled at 50% (50/100) in 0s\n")
+
+	bar.Done()
+	if strings.Count(out.String(), "\n") != 1 {
+		t.Errorf("Expected no output after cancellation, got %q", out.String())
+	}
+}
+
+func TestProgress_Stop(t *testing.T) {
+	cmd, out, _ := newProgressCommand(false)
+	g := cmd.NewProgressGroup(t.Context())
+	g.Add("a", 0)
+	g.Stop()
+	g.Stop()
+	if out.String() != "a: canceled (0s)\n" {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+	if bar := g.Add("b", 1); bar.state != progressCanceled {
+		t.Error("Expected bars added after Stop to be canceled")
+	}
+}
+
+func TestProgress_Writer(t *testing.T) {
+	cmd, out, _ := newProgressCommand(false)
+	bar := cmd.NewProgress(t.Context(), "download", 11)
+	if _, err := io.Copy(io.Discard, io.TeeReader(strings.NewReader("hello world"), bar)); err != nil {
+		t.Fatal(err)
+	}
+	if bar.current != 11 {
+		t.Errorf("Expected 11 bytes counted, got %d", bar.current)
+	}
+	bar.Done()
+	if !strings.Contains(out.String(), "download: done at 100% (11/11)") {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+}
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Prompter 在终端上询问用户输入
+//
+// 提示信息写到 Out（通常是标准错误，避免混入命令输出），回答从 In 逐行读取。
+// 测试时可以通过 In 注入脚本化的输入。
+type Prompter struct {
+	In  io.Reader // 输入
+	Out io.Writer // 提示输出
+}
+
+// Prompter 返回使用命令输入流和标准错误的 Prompter
+func (c *Command) Prompter() *Prompter {
+	return &Prompter{In: c.Stdin(), Out: c.Stderr()}
+}
+
+// CanPrompt 判断本次调用是否允许交互式询问
+//
+// 需要命令启用 Interactive、未指定 --no-input，且标准输入为终端。
+func (c *Command) CanPrompt() bool {
+	return c.Interactive && !c.noInput && isTerminal(c.Stdin())
+}
+
+// Text 询问文本，直接回车时返回默认值
+func (p *Prompter) Text(label, def string) (string, error) {
+	if def != "" {
+		p.printf("%s [%s]: ", label, def)
+	} else {
+		p.printf("%s: ", label)
+	}
+	line, err := p.readLine()
+	if err != nil {
+		return "", err
+	}
+	if line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+// Password 询问密码，输入为终端时关闭回显（无法关闭时返回错误）
+func (p *Prompter) Password(label string) (string, error) {
+	p.printf("%s: ", label)
+	if f, ok := p.In.(*os.File); ok && isTerminal(f) {
+		// 无法关闭回显时不读取，避免密码显示在屏幕上
+		restore, err := disableEcho(f.Fd())
+		if err != nil {
+			p.printf("\n")
+			return "", fmt.Errorf("cannot hide password input: %w", err)
+		}
+		defer func() {
+			restore()
+			p.printf("\n")
+		}()
+	}
+	return p.readLine()
+}
+
+// Confirm 询问是/否，直接回车时返回默认值
+func (p *Prompter) Confirm(label string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		p.printf("%s [%s]: ", label, hint)
+		line, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(line) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		p.printf("Please answer yes or no.\n")
+	}
+}
+
+// Select 从选项中单选，可以输入序号或选项值，直接回车时返回默认值
+func (p *Prompter) Select(label string, options []string, def string) (string, error) {
+	if len(options) == 0 {
+		return "", errors.New("select: no options")
+	}
+	for {
+		p.printOptions(label, options)
+		if def != "" {
+			p.printf("Choose one [%s]: ", def)
+		} else {
+			p.printf("Choose one: ")
+		}
+		line, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if line == "" && def != "" {
+			return def, nil
+		}
+		if choice, ok := pickOption(options, line); ok {
+			return choice, nil
+		}
+		p.printf("Invalid choice %q.\n", line)
+	}
+}
+
+// MultiSelect 从选项中多选，以逗号分隔序号或选项值，直接回车时返回默认值
+func (p *Prompter) MultiSelect(label string, options []string, defs []string) ([]string, error) {
+	if len(options) == 0 {
+		return nil, errors.New("multi-select: no options")
+	}
+	for {
+		p.printOptions(label, options)
+		if len(defs) > 0 {
+			p.printf("Choose any, separated by commas [%s]: ", strings.Join(defs, ","))
+		} else {
+			p.printf("Choose any, separated by commas: ")
+		}
+		line, err := p.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			return defs, nil
+		}
+
+		var chosen []string
+		valid := true
+		for part := range strings.SplitSeq(line, ",") {
+			choice, ok := pickOption(options, strings.TrimSpace(part))
+			if !ok {
+				p.printf("Invalid choice %q.\n", strings.TrimSpace(part))
+				valid = false
+				break
+			}
+			if !slices.Contains(chosen, choice) {
+				chosen = append(chosen, choice)
+			}
+		}
+		if valid {
+			return chosen, nil
+		}
+	}
+}
+
+// printOptions 打印带序号的选项列表
+func (p *Prompter) printOptions(label string, options []string) {
+	p.printf("%s:\n", label)
+	for i, opt := range options {
+		p.printf("  %d) %s\n", i+1, opt)
+	}
+}
+
+// printf 输出提示信息（忽略写入错误）
+func (p *Prompter) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(p.Out, format, args...)
+}
+
+// readLine 逐字节读取一行（不缓冲，避免吞掉后续提示的输入）
+func (p *Prompter) readLine() (string, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := p.In.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return strings.TrimRight(string(line), "\r"), nil
+			}
+			line = append(line, buf[0])
+		}
+		if err == io.EOF {
+			if len(line) > 0 {
+				return strings.TrimRight(string(line), "\r"), nil
+			}
+			return "", io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// pickOption 按序号或值匹配选项
+func pickOption(options []string, input string) (string, bool) {
+	if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(options) {
+		return options[n-1], true
+	}
+	if slices.Contains(options, input) {
+		return input, true
+	}
+	return "", false
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ttyReader 模拟终端输入的脚本化 Reader
+type ttyReader struct {
+	io.Reader
+}
+
+func (ttyReader) IsTerminal() bool { return true }
+
+func newTestPrompter(input string) (*Prompter, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	return &Prompter{In: strings.NewReader(input), Out: out}, out
+}
+
+func TestPrompter_Text(t *testing.T) {
+	p, out := newTestPrompter("alice\n\n")
+
+	v, err := p.Text("Name", "bob")
+	if err != nil || v != "alice" {
+		t.Errorf("Expected 'alice', got %q (%v)", v, err)
+	}
+	v, err = p.Text("Name", "bob")
+	if err != nil || v != "bob" {
+		t.Errorf("Expected default 'bob', got %q (%v)", v, err)
+	}
+	if !strings.Contains(out.String(), "Name [bob]: ") {
+		t.Errorf("Expected prompt with default, got %q", out.String())
+	}
+}
+
+func TestPrompter_TextEOF(t *testing.T) {
+	p, _ := newTestPrompter("")
+
+	if _, err := p.Text("Name", ""); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestPrompter_Password(t *testing.T) {
+	p, _ := newTestPrompter("s3cr3t\r\n")
+
+	v, err := p.Password("Password")
+	if err != nil || v != "s3cr3t" {
+		t.Errorf("Expected 's3cr3t', got %q (%v)", v, err)
+	}
+}
+
+func TestPrompter_Confirm(t *testing.T) {
+	p, out := newTestPrompter("maybe\nYes\n\n")
+
+	v, err := p.Confirm("Continue?", false)
+	if err != nil || !v {
+		t.Errorf("Expected true after retry, got %v (%v)", v, err)
+	}
+	if !strings.Contains(out.String(), "Please answer yes or no.") {
+		t.Error("Expected retry message for invalid answer")
+	}
+	v, err = p.Confirm("Continue?", true)
+	if err != nil || !v {
+		t.Errorf("Expected default true, got %v (%v)", v, err)
+	}
+}
+
+func TestPrompter_Select(t *testing.T) {
+	p, out := newTestPrompter("4\n2\nprod\n")
+	options := []string{"dev", "staging", "prod"}
+
+	v, err := p.Select("Environment", options, "")
+	if err != nil || v != "staging" {
+		t.Errorf("Expected 'staging', got %q (%v)", v, err)
+	}
+	if !strings.Contains(out.String(), "  3) prod") || !strings.Contains(out.String(), `Invalid choice "4"`) {
+		t.Errorf("Expected numbered options and retry, got %q", out.String())
+	}
+	v, err = p.Select("Environment", options, "")
+	if err != nil || v != "prod" {
+		t.Errorf("Expected 'prod' by value, got %q (%v)", v, err)
+	}
+}
+
+func TestPrompter_MultiSelect(t *testing.T) {
+	p, _ := newTestPrompter("1, db ,1\n\n")
+	options := []string{"web", "db", "cache"}
+
+	v, err := p.MultiSelect("Services", options, nil)
+	if err != nil || !slices.Equal(v, []string{"web", "db"}) {
+		t.Errorf("Expected [web db], got %v (%v)", v, err)
+	}
+	v, err = p.MultiSelect("Services", options, []string{"cache"})
+	if err != nil || !slices.Equal(v, []string{"cache"}) {
+		t.Errorf("Expected default [cache], got %v (%v)", v, err)
+	}
+}
+
+func TestCommand_CanPrompt(t *testing.T) {
+	cmd := NewCommand("test", "Test command")
+	cmd.SetStdin(ttyReader{strings.NewReader("")})
+	if cmd.CanPrompt() {
+		t.Error("Expected prompts to be disabled unless Interactive is set")
+	}
+
+	cmd.Interactive = true
+	if !cmd.CanPrompt() {
+		t.Error("Expected prompts to be enabled on a terminal")
+	}
+
+	cmd.SetStdin(strings.NewReader(""))
+	if cmd.CanPrompt() {
+		t.Error("Expected prompts to be disabled on non-terminal stdin")
+	}
+}
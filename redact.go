@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"flag"
+	"slices"
+	"strings"
+)
+
+// redactedValue 替换敏感参数值的占位符
+const redactedValue = "***"
+
+// secretFlagWords 被视为敏感标志的名称片段
+//
+// 按 "-"、"_"、"." 拆分后的整段比较，避免 author 之类的名称被误判。
+var secretFlagWords = []string{
+	"password", "passwd", "secret", "token", "apikey", "credential", "credentials", "auth",
+}
+
+// isSecretFlag 判断标志名称是否敏感
+//
+// 名称的某一段为常见敏感词（password、token、secret 等，api-key 也算），
+// 或出现在 Program.SecretFlags 中的标志都视为敏感。
+func (p *Program) isSecretFlag(name string) bool {
+	if slices.Contains(p.SecretFlags, name) {
+		return true
+	}
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+	for i, word := range words {
+		if slices.Contains(secretFlagWords, word) {
+			return true
+		}
+		if word == "api" && i+1 < len(words) && words[i+1] == "key" {
+			return true
+		}
+	}
+	return false
+}
+
+// redactArgs 返回将敏感标志值替换为 *** 后的参数副本
+//
+// 支持 -name=value、--name=value 以及 -name value 三种写法，"--" 之后的参数原样保留。
+// fs 为命令的标志集合：其中定义的标志按定义判断是否带值，
+// 布尔标志不会吞掉后面的参数，带值的敏感标志即使值以 "-" 开头也会被替换。
+// 未定义的标志（或 fs 为 nil）只在下一个参数不像标志时视为带值。
+func (p *Program) redactArgs(fs *flag.FlagSet, args []string) []string {
+	out := make([]string, len(args))
+	copy(out, args)
+
+	for i := 0; i < len(out); i++ {
+		arg := out[i]
+		if arg == "--" {
+			break
+		}
+		if !isFlag(arg) {
+			continue
+		}
+		name, _, hasValue := splitFlagArg(arg)
+		if !p.isSecretFlag(name) {
+			continue
+		}
+		var def *flag.Flag
+		if fs != nil {
+			def = fs.Lookup(name)
+		}
+		if def != nil && isBoolFlag(def) {
+			continue
+		}
+		switch {
+		case hasValue:
+			out[i] = arg[:strings.IndexByte(arg, '=')+1] + redactedValue
+		case i+1 < len(out) && (def != nil || !isFlag(out[i+1])):
+			out[i+1] = redactedValue
+			i++
+		}
+	}
+
+	return out
+}
+
+// isBoolFlag 判断标志是否为不需要值的布尔标志
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"flag"
+	"slices"
+	"testing"
+)
+
+func TestProgram_RedactArgs(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SecretFlags = []string{"dsn"}
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{
+			[]string{"app", "login", "--password", "hunter2", "--user", "bob"},
+			[]string{"app", "login", "--password", "***", "--user", "bob"},
+		},
+		{
+			[]string{"app", "deploy", "-api-token=abc", "-env=prod"},
+			[]string{"app", "deploy", "-api-token=***", "-env=prod"},
+		},
+		{
+			[]string{"app", "db", "--dsn", "postgres://u:p@host"},
+			[]string{"app", "db", "--dsn", "***"},
+		},
+		{
+			[]string{"app", "run", "--", "--token", "literal"},
+			[]string{"app", "run", "--", "--token", "literal"},
+		},
+		{
+			[]string{"app", "run", "--secret", "--verbose"},
+			[]string{"app", "run", "--secret", "--verbose"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := prog.redactArgs(nil, tt.args)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("redactArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestProgram_RedactArgsDoesNotModifyInput(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	args := []string{"app", "--token", "abc"}
+
+	_ = prog.redactArgs(nil, args)
+	if args[2] != "abc" {
+		t.Error("Expected original args to be left untouched")
+	}
+}
+
+func TestProgram_RedactArgsUsesFlagDefinitions(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	fs.String("token", "", "API token")
+	fs.Bool("secret", false, "Read the secret from stdin")
+	fs.String("author", "", "Commit author")
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{
+			[]string{"-token", "-abc"},
+			[]string{"-token", "***"},
+		},
+		{
+			[]string{"--secret", "prod"},
+			[]string{"--secret", "prod"},
+		},
+		{
+			[]string{"--author", "ada"},
+			[]string{"--author", "ada"},
+		},
+		{
+			[]string{"--token=abc", "--author=ada"},
+			[]string{"--token=***", "--author=ada"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := prog.redactArgs(fs, tt.args)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("redactArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestProgram_IsSecretFlag(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SecretFlags = []string{"dsn"}
+
+	tests := map[string]bool{
+		"password":     true,
+		"db-password":  true,
+		"access_token": true,
+		"api-key":      true,
+		"apikey":       true,
+		"auth":         true,
+		"dsn":          true,
+		"author":       false,
+		"tokenizer":    false,
+		"key":          false,
+		"user":         false,
+	}
+
+	for name, want := range tests {
+		if got := prog.isSecretFlag(name); got != want {
+			t.Errorf("isSecretFlag(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
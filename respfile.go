@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// expandResponseFiles 将参数中的 @path 替换为从文件中读取的参数
+//
+// 文件中每行一个参数，按原样使用（去掉首尾空白，反斜杠和单引号没有特殊含义）；
+// 以引号开头的行按 shell 规则拆分，可以在一行中写多个参数或保留首尾空白。
+// 空行和 # 开头的注释会被忽略。文件中的 @path 会继续展开，相对路径基于所在文件的目录；
+// 出现循环引用时返回错误。@@ 开头的参数表示以 @ 开头的普通参数（去掉一个 @）。
+// 命令行中的相对路径基于 dir。"--" 之后的参数（包括文件中的 "--" 之后）原样保留。
+func expandResponseFiles(args []string, dir string) ([]string, error) {
+	e := &responseExpander{}
+	return e.expand(args, dir, nil)
+}
+
+// responseExpander 展开响应文件的状态
+type responseExpander struct {
+	done bool // 已遇到 "--"，之后的参数不再展开
+}
+
+// expand 展开 args 中的 @path，dir 为相对路径的基准目录，stack 为正在展开的文件
+func (e *responseExpander) expand(args []string, dir string, stack []string) ([]string, error) {
+	var out []string
+	for _, arg := range args {
+		switch {
+		case e.done:
+			out = append(out, arg)
+		case arg == "--":
+			e.done = true
+			out = append(out, arg)
+		case strings.HasPrefix(arg, "@@"):
+			out = append(out, arg[1:])
+		case len(arg) > 1 && arg[0] == '@':
+			expanded, err := e.read(arg[1:], dir, stack)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, expanded...)
+		default:
+			out = append(out, arg)
+		}
+	}
+	return out, nil
+}
+
+// read 读取并展开一个响应文件
+func (e *responseExpander) read(name, dir string, stack []string) ([]string, error) {
+	path := name
+	if !filepath.IsAbs(path) && dir != "" {
+		path = filepath.Join(dir, path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("response file @%s: %w", name, err)
+	}
+	for i, seen := range stack {
+		if seen == abs {
+			chain := append(stack[i:], abs)
+			return nil, fmt.Errorf("response file cycle: %s", strings.Join(chain, " -> "))
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("response file @%s: %w", name, err)
+	}
+
+	var args []string
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || line[0] == '#':
+			continue
+		case line[0] == '\'' || line[0] == '"':
+			words, err := splitArgsWith(line, splitOptions{comments: true})
+			if err != nil {
+				return nil, fmt.Errorf("response file %s:%d: %w", path, i+1, err)
+			}
+			args = append(args, words...)
+		default:
+			args = append(args, line)
+		}
+	}
+	return e.expand(args, filepath.Dir(abs), append(stack[:len(stack):len(stack)], abs))
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExpandResponseFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "args.txt"), "--env\nproduction\n\n# hosts\n'--message' 'hello world'\n@sub/more.txt\n")
+	writeFile(t, filepath.Join(dir, "sub", "more.txt"), "--host=a\n  --host=b  \n@@literal\n")
+
+	got, err := expandResponseFiles([]string{"--verbose", "@" + filepath.Join(dir, "args.txt"), "@@user", "@", "last"}, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []string{
+		"--verbose", "--env", "production", "--message", "hello world",
+		"--host=a", "--host=b", "@literal", "@user", "@", "last",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestExpandResponseFilesLines(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "args.txt"), "--message=it's done\r\nC:\\Users\\ada\\notes.txt\nhello world\n\"  padded  \" x # comment\n--\n@literal.txt\n")
+
+	got, err := expandResponseFiles([]string{"@" + filepath.Join(dir, "args.txt"), "@more.txt"}, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []string{
+		"--message=it's done", `C:\Users\ada\notes.txt`, "hello world", "  padded  ", "x",
+		"--", "@literal.txt", "@more.txt",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// 命令行中 "--" 之后的 @path 原样保留
+	got, err = expandResponseFiles([]string{"run", "--", "@" + filepath.Join(dir, "args.txt")}, "")
+	if err != nil || !slices.Equal(got, []string{"run", "--", "@" + filepath.Join(dir, "args.txt")}) {
+		t.Errorf("Expected @path after -- to be left alone, got %q (%v)", got, err)
+	}
+}
+
+func TestExpandResponseFilesErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.txt"), "--x\n@b.txt\n")
+	writeFile(t, filepath.Join(dir, "b.txt"), "@a.txt\n")
+	writeFile(t, filepath.Join(dir, "self.txt"), "@self.txt\n")
+	writeFile(t, filepath.Join(dir, "bad.txt"), "ok\n'unterminated\n")
+	writeFile(t, filepath.Join(dir, "twice.txt"), "@one.txt\n@one.txt\n")
+	writeFile(t, filepath.Join(dir, "one.txt"), "1\n")
+
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"@" + filepath.Join(dir, "a.txt"), "response file cycle: " + filepath.Join(dir, "a.txt") + " -> " + filepath.Join(dir, "b.txt") + " -> " + filepath.Join(dir, "a.txt")},
+		{"@" + filepath.Join(dir, "self.txt"), "response file cycle"},
+		{"@" + filepath.Join(dir, "bad.txt"), "bad.txt:2: unterminated quote"},
+		{"@" + filepath.Join(dir, "missing.txt"), "response file @" + filepath.Join(dir, "missing.txt")},
+	}
+	for _, tt := range tests {
+		_, err := expandResponseFiles([]string{tt.arg}, "")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("expandResponseFiles(%q): expected error containing %q, got %v", tt.arg, tt.want, err)
+		}
+	}
+
+	// 同一文件被引用多次不是循环
+	got, err := expandResponseFiles([]string{"@" + filepath.Join(dir, "twice.txt")}, "")
+	if err != nil || !slices.Equal(got, []string{"1", "1"}) {
+		t.Errorf("Expected repeated file to expand twice, got %q (%v)", got, err)
+	}
+}
+
+func TestProgram_ResponseFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deploy.args")
+	writeFile(t, path, "--env\nproduction\n--tag\n@@latest\nweb-1\nweb-2\n")
+
+	var env, tag string
+	var hosts []string
+	deployCmd := NewCommand("deploy", "Deploy")
+	deployCmd.Flags.StringVar(&env, "env", "", "Environment")
+	deployCmd.Flags.StringVar(&tag, "tag", "", "Tag")
+	deployCmd.Action = func(ctx context.Context, cmd *Command) error {
+		hosts = cmd.Flags.Args()
+		return nil
+	}
+
+	prog := NewProgram("testapp", "1.0.0")
+	prog.Commands = []*Command{deployCmd}
+
+	// 默认不展开
+	if err := prog.Run([]string{"testapp", "deploy", "@" + path}); err != nil {
+		t.Fatal(err)
+	}
+	if env != "" || !slices.Equal(hosts, []string{"@" + path}) {
+		t.Errorf("Expected @path to be left alone by default, got env=%q hosts=%q", env, hosts)
+	}
+
+	prog.ResponseFiles = true
+	if err := prog.Run([]string{"testapp", "deploy", "@" + path, "@@web-3"}); err != nil {
+		t.Fatal(err)
+	}
+	if env != "production" || tag != "@latest" || !slices.Equal(hosts, []string{"web-1", "web-2", "@web-3"}) {
+		t.Errorf("Unexpected values: env=%q tag=%q hosts=%q", env, tag, hosts)
+	}
+
+	// 命令名称也可以来自响应文件
+	writeFile(t, filepath.Join(dir, "full.args"), "'deploy' --env staging\n")
+	if err := prog.Run([]string{"testapp", "@" + filepath.Join(dir, "full.args")}); err != nil {
+		t.Fatal(err)
+	}
+	if env != "staging" {
+		t.Errorf("Expected env from response file, got %q", env)
+	}
+
+	if err := prog.Run([]string{"testapp", "deploy", "@" + filepath.Join(dir, "missing")}); err == nil {
+		t.Error("Expected error for missing response file")
+	}
+}
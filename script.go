@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScriptStep 脚本中一个步骤的执行结果
+type ScriptStep struct {
+	Line     int           // 所在行号（从 1 开始）
+	Command  string        // 原始命令行（变量展开前，避免泄露环境变量中的敏感值）
+	Duration time.Duration // 执行耗时
+	ExitCode int           // 退出码（见 ExitCode）
+	Err      error         // 执行错误
+	Skipped  bool          // 因前面的步骤失败而未执行
+}
+
+// ScriptReport 脚本的执行报告
+type ScriptReport struct {
+	Steps    []ScriptStep  // 各步骤的结果（按脚本顺序）
+	Duration time.Duration // 总耗时
+}
+
+// Failed 返回失败的步骤数
+func (r *ScriptReport) Failed() int {
+	n := 0
+	for _, step := range r.Steps {
+		if step.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteSummary 以表格形式写出每个步骤的状态、退出码和耗时
+func (r *ScriptReport) WriteSummary(w io.Writer) error {
+	t := &Table{Header: []string{"LINE", "STATUS", "EXIT", "DURATION", "COMMAND"}}
+	for _, step := range r.Steps {
+		status, exit := "ok", strconv.Itoa(step.ExitCode)
+		switch {
+		case step.Skipped:
+			status, exit = "skipped", "-"
+		case step.Err != nil:
+			status = "failed"
+		}
+		t.Rows = append(t.Rows, []string{
+			strconv.Itoa(step.Line), status, exit, formatDuration(step.Duration), step.Command,
+		})
+	}
+
+	var b strings.Builder
+	b.WriteString("\nSCRIPT SUMMARY:\n")
+	if err := t.write(&b); err != nil {
+		return err
+	}
+	fmt.Fprintf(&b, "\n%d steps, %d failed, finished in %s\n", len(r.Steps), r.Failed(), formatDuration(r.Duration))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ScriptError 表示脚本中有步骤失败
+//
+// Step 为第一个失败的步骤，ExitCode 返回该步骤的退出码。
+type ScriptError struct {
+	Step   ScriptStep
+	Failed int // 失败的步骤总数
+}
+
+// Error 实现 error 接口
+func (e *ScriptError) Error() string {
+	msg := fmt.Sprintf("script line %d: %v", e.Step.Line, e.Step.Err)
+	if e.Failed > 1 {
+		msg += fmt.Sprintf(" (%d steps failed)", e.Failed)
+	}
+	return msg
+}
+
+// Unwrap 返回失败步骤的错误
+func (e *ScriptError) Unwrap() error {
+	return e.Step.Err
+}
+
+// ExitCode 实现 ExitCoder 接口
+func (e *ScriptError) ExitCode() int {
+	return e.Step.ExitCode
+}
+
+// scriptLine 解析后的一行脚本
+type scriptLine struct {
+	line int
+	text string
+	args []string
+	stop *bool // 非 nil 时为 set -e / set +e 指令
+}
+
+// RunScript 逐行执行脚本中的命令
+//
+// 每行按 shell 规则拆分，通过与 RunContext 相同的路由执行。# 开头的单词及其后的内容为注释，
+// 引号外和双引号内的 $NAME、${NAME} 会被替换为环境变量（单引号内原样保留）。
+// 默认某个步骤失败后继续执行后续步骤；"set -e" 之后改为在失败处停止，"set +e" 恢复继续执行。
+//
+// 执行前会先检查整个脚本的语法，有错误时不执行任何步骤。有步骤失败时返回 *ScriptError，
+// 报告中包含每个步骤的耗时和退出码，可以通过 ScriptReport.WriteSummary 输出。
+func (p *Program) RunScript(ctx context.Context, r io.Reader) (*ScriptReport, error) {
+	return p.runScript(ctx, r, false)
+}
+
+// runScript 执行脚本，stopOnError 为初始的 set -e 状态
+func (p *Program) runScript(ctx context.Context, r io.Reader, stopOnError bool) (*ScriptReport, error) {
+	lines, err := parseScript(r, p.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ScriptReport{}
+	start := p.Now()
+	defer func() {
+		report.Duration = p.Now().Sub(start)
+	}()
+
+	var first *ScriptStep
+	stopped := false
+	for _, l := range lines {
+		if l.stop != nil {
+			stopOnError = *l.stop
+			continue
+		}
+
+		step := ScriptStep{Line: l.line, Command: l.text}
+		if stopped {
+			step.Skipped = true
+			report.Steps = append(report.Steps, step)
+			continue
+		}
+
+		stepStart := p.Now()
+		step.Err = p.runAudited(ctx, append([]string{p.Name}, l.args...), p.run)
+		step.Duration = p.Now().Sub(stepStart)
+		step.ExitCode = ExitCode(step.Err)
+		report.Steps = append(report.Steps, step)
+
+		if step.Err != nil {
+			p.printError("", "Error: line %d: %v", l.line, step.Err)
+			if first == nil {
+				first = &report.Steps[len(report.Steps)-1]
+			}
+			// context 已取消时后续步骤也无法执行
+			stopped = stopOnError || ctx.Err() != nil
+		}
+	}
+
+	if first != nil {
+		return report, &ScriptError{Step: *first, Failed: report.Failed()}
+	}
+	return report, nil
+}
+
+// parseScript 读取并拆分整个脚本，变量从 getenv 读取
+func parseScript(r io.Reader, getenv func(string) string) ([]scriptLine, error) {
+	opts := splitOptions{comments: true, getenv: getenv}
+
+	var lines []scriptLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	n := 0
+	for scanner.Scan() {
+		n++
+		text := strings.TrimSpace(scanner.Text())
+		args, err := splitArgsWith(text, opts)
+		if err != nil {
+			return nil, fmt.Errorf("script line %d: %w", n, err)
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		l := scriptLine{line: n, text: text, args: args}
+		if len(args) == 2 && args[0] == "set" && (args[1] == "-e" || args[1] == "+e") {
+			stop := args[1] == "-e"
+			l.stop = &stop
+		}
+		lines = append(lines, l)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// scriptCommand 创建内置的 run-script 命令
+func (p *Program) scriptCommand() *Command {
+	var stopOnError, quiet bool
+
+	cmd := NewCommand("run-script", "Run commands from a script file")
+	cmd.Description = "Run one command per line from a file ('-' for standard input) and print a summary"
+	cmd.HideOutputFlag = true
+	cmd.Flags.BoolVar(&stopOnError, "e", false, "Stop at the first failing step (same as 'set -e')")
+	cmd.Flags.BoolVar(&quiet, "q", false, "Do not print the summary")
+	cmd.Params = []Param{{Name: "file", Positional: true, Required: true}}
+	cmd.Action = func(ctx context.Context, cmd *Command) error {
+		var in io.Reader = cmd.Stdin()
+		if name := cmd.Flags.Arg(0); name != "-" {
+			f, err := os.Open(cmd.Path(name))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+
+		report, err := p.runScript(ctx, in, stopOnError)
+		if report != nil && !quiet {
+			if werr := report.WriteSummary(cmd.Stderr()); werr != nil && err == nil {
+				err = werr
+			}
+		}
+		return err
+	}
+	return cmd
+}
+
+// formatDuration 以适合阅读的精度格式化耗时
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newScriptProgram() (*Program, *bytes.Buffer, *bytes.Buffer) {
+	prog := NewProgram("testapp", "1.0.0")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	prog.SetStdout(stdout)
+	prog.SetStderr(stderr)
+
+	var name string
+	greetCmd := NewCommand("greet", "Greet someone")
+	greetCmd.Flags.StringVar(&name, "name", "world", "Name")
+	greetCmd.Action = func(ctx context.Context, cmd *Command) error {
+		_, err := cmd.Stdout().Write([]byte("hello " + name + "\n"))
+		return err
+	}
+
+	failCmd := NewCommand("fail", "Always fail")
+	failCmd.Action = func(ctx context.Context, cmd *Command) error {
+		return &testExitError{code: 3}
+	}
+
+	prog.Commands = []*Command{greetCmd, failCmd}
+	return prog, stdout, stderr
+}
+
+type testExitError struct {
+	code int
+}
+
+func (e *testExitError) Error() string { return fmt.Sprintf("exit status %d", e.code) }
+func (e *testExitError) ExitCode() int { return e.code }
+
+func TestProgram_RunScript(t *testing.T) {
+	t.Setenv("SCRIPT_NAME", "Ada Lovelace")
+	prog, stdout, stderr := newScriptProgram()
+
+	script := `# greet everyone
+greet -name "$SCRIPT_NAME"
+
+greet   # uses the default name
+fail
+greet -name '$SCRIPT_NAME'
+`
+	report, err := prog.RunScript(context.Background(), strings.NewReader(script))
+
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) {
+		t.Fatalf("Expected *ScriptError, got %v", err)
+	}
+	if scriptErr.Step.Line != 5 || ExitCode(err) != 3 {
+		t.Errorf("Expected failure on line 5 with exit code 3, got line %d, code %d", scriptErr.Step.Line, ExitCode(err))
+	}
+
+	// 默认在失败后继续执行
+	want := "hello Ada Lovelace\nhello world\nhello $SCRIPT_NAME\n"
+	if stdout.String() != want {
+		t.Errorf("Unexpected output: %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Error: line 5: exit status 3") {
+		t.Errorf("Expected step error on stderr, got %q", stderr.String())
+	}
+
+	if len(report.Steps) != 4 || report.Failed() != 1 {
+		t.Fatalf("Unexpected report: %+v", report.Steps)
+	}
+	if report.Steps[0].Command != `greet -name "$SCRIPT_NAME"` {
+		t.Errorf("Expected unexpanded command in report, got %q", report.Steps[0].Command)
+	}
+	if report.Steps[2].ExitCode != 3 || report.Steps[3].ExitCode != 0 {
+		t.Errorf("Unexpected exit codes: %+v", report.Steps)
+	}
+}
+
+func TestProgram_RunScriptStopOnError(t *testing.T) {
+	prog, stdout, _ := newScriptProgram()
+
+	script := "greet\nset -e\nfail\ngreet -name skipped\n"
+	report, err := prog.RunScript(context.Background(), strings.NewReader(script))
+	if ExitCode(err) != 3 {
+		t.Errorf("Expected exit code 3, got %v", err)
+	}
+	if stdout.String() != "hello world\n" {
+		t.Errorf("Expected steps after failure to be skipped, got %q", stdout.String())
+	}
+	if len(report.Steps) != 3 || !report.Steps[2].Skipped {
+		t.Errorf("Expected last step to be reported as skipped, got %+v", report.Steps)
+	}
+
+	// set +e 恢复继续执行
+	stdout.Reset()
+	_, _ = prog.RunScript(context.Background(), strings.NewReader("set -e\nset +e\nfail\ngreet\n"))
+	if stdout.String() != "hello world\n" {
+		t.Errorf("Expected set +e to continue after failure, got %q", stdout.String())
+	}
+}
+
+func TestProgram_RunScriptSyntaxError(t *testing.T) {
+	prog, stdout, _ := newScriptProgram()
+
+	report, err := prog.RunScript(context.Background(), strings.NewReader("greet\ngreet -name 'oops\n"))
+	if err == nil || !strings.Contains(err.Error(), "script line 2: unterminated quote") {
+		t.Errorf("Expected syntax error for line 2, got %v", err)
+	}
+	if report != nil || stdout.Len() != 0 {
+		t.Errorf("Expected no steps to run, got output %q", stdout.String())
+	}
+}
+
+func TestProgram_RunScriptCanceled(t *testing.T) {
+	prog, _, _ := newScriptProgram()
+	ctx, cancel := context.WithCancel(context.Background())
+	prog.Commands[1].Action = func(ctx context.Context, cmd *Command) error {
+		cancel()
+		return ctx.Err()
+	}
+
+	report, err := prog.RunScript(ctx, strings.NewReader("fail\ngreet\n"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if !report.Steps[1].Skipped {
+		t.Errorf("Expected remaining steps to be skipped after cancel")
+	}
+}
+
+func TestProgram_RunScriptCommand(t *testing.T) {
+	prog, stdout, stderr := newScriptProgram()
+	prog.EnableScript = true
+
+	path := filepath.Join(t.TempDir(), "steps.txt")
+	if err := os.WriteFile(path, []byte("greet -name a\nfail\ngreet -name b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := prog.Run([]string{"testapp", "run-script", "-e", path})
+	if ExitCode(err) != 3 {
+		t.Errorf("Expected exit code 3, got %v", err)
+	}
+	if stdout.String() != "hello a\n" {
+		t.Errorf("Unexpected output: %q", stdout.String())
+	}
+	summary := stderr.String()
+	for _, want := range []string{"SCRIPT SUMMARY:", "LINE   STATUS", "failed    3", "skipped   -", "3 steps, 1 failed"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, summary)
+		}
+	}
+
+	// 从标准输入读取脚本，-q 不输出汇总
+	stdout.Reset()
+	stderr.Reset()
+	prog.SetStdin(strings.NewReader("greet -name stdin\n"))
+	if err := prog.Run([]string{"testapp", "run-script", "-q", "-"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stdout.String() != "hello stdin\n" || stderr.Len() != 0 {
+		t.Errorf("Unexpected output: %q, stderr %q", stdout.String(), stderr.String())
+	}
+
+	if err := prog.Run([]string{"testapp", "run-script"}); err == nil {
+		t.Error("Expected error when script file is missing")
+	}
+}
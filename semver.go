@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// semver 语义化版本（https://semver.org），构建元数据不参与比较
+type semver struct {
+	major, minor, patch uint64
+	pre                 []string // 预发布标识符（如 beta.1 → [beta 1]）
+}
+
+// parseSemver 解析语义化版本，允许 v 前缀
+func parseSemver(s string) (semver, error) {
+	var v semver
+	rest := strings.TrimPrefix(s, "v")
+	rest, _, _ = strings.Cut(rest, "+")
+	rest, pre, hasPre := strings.Cut(rest, "-")
+
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("invalid version %q", s)
+	}
+	nums := make([]uint64, 3)
+	for i, part := range parts {
+		n, err := parseNumericIdent(part)
+		if err != nil {
+			return v, fmt.Errorf("invalid version %q", s)
+		}
+		nums[i] = n
+	}
+	v.major, v.minor, v.patch = nums[0], nums[1], nums[2]
+
+	if hasPre {
+		v.pre = strings.Split(pre, ".")
+		for _, id := range v.pre {
+			if id == "" || strings.Trim(id, "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ-") != "" {
+				return v, fmt.Errorf("invalid version %q", s)
+			}
+		}
+	}
+	return v, nil
+}
+
+// parseNumericIdent 解析数字标识符（不允许前导零）
+func parseNumericIdent(s string) (uint64, error) {
+	if len(s) > 1 && s[0] == '0' {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// prerelease 判断是否为预发布版本
+func (v semver) prerelease() bool {
+	return len(v.pre) > 0
+}
+
+// compare 比较两个版本，返回 -1、0 或 1
+func (v semver) compare(w semver) int {
+	for _, d := range [][2]uint64{{v.major, w.major}, {v.minor, w.minor}, {v.patch, w.patch}} {
+		if d[0] != d[1] {
+			if d[0] < d[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// 预发布版本低于对应的正式版本
+	switch {
+	case len(v.pre) == 0 && len(w.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(w.pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.pre) && i < len(w.pre); i++ {
+		if c := comparePreIdent(v.pre[i], w.pre[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(v.pre) < len(w.pre):
+		return -1
+	case len(v.pre) > len(w.pre):
+		return 1
+	}
+	return 0
+}
+
+// comparePreIdent 比较预发布标识符：数字按数值比较且低于非数字标识符
+func comparePreIdent(a, b string) int {
+	an, aerr := parseNumericIdent(a)
+	bn, berr := parseNumericIdent(b)
+	switch {
+	case aerr == nil && berr == nil:
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+		return 0
+	case aerr == nil:
+		return -1
+	case berr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,49 @@
+package cli
+
+import "testing"
+
+func TestParseSemver(t *testing.T) {
+	valid := []string{"1.2.3", "v1.2.3", "0.0.0", "1.2.3-beta.1", "1.2.3-rc-1+build.5", "10.20.30+meta"}
+	for _, s := range valid {
+		if _, err := parseSemver(s); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", s, err)
+		}
+	}
+
+	invalid := []string{"", "dev", "1.2", "1.2.3.4", "01.2.3", "1.2.x", "1.2.3-", "1.2.3-beta..1", "1.2.3-beta_1"}
+	for _, s := range invalid {
+		if _, err := parseSemver(s); err == nil {
+			t.Errorf("Expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestSemver_Compare(t *testing.T) {
+	// 按 semver.org 规定的优先级从低到高排列
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := parseSemver(ordered[i])
+			b, _ := parseSemver(ordered[j])
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			if got := a.compare(b); got != want {
+				t.Errorf("compare(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	a, _ := parseSemver("v1.2.3+build.1")
+	b, _ := parseSemver("1.2.3+build.2")
+	if a.compare(b) != 0 {
+		t.Error("Expected build metadata to be ignored")
+	}
+}
@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// maxShellHistory 内存中保留的历史记录条数
+const maxShellHistory = 1000
+
+// shellKey 标记 context 处于交互式命令行中
+type shellKey struct{}
+
+// shellCommand 创建内置的 shell 命令
+func (p *Program) shellCommand() *Command {
+	cmd := NewCommand("shell", "Start an interactive shell")
+	cmd.Description = "Read commands line by line and run them, type 'exit' or 'quit' to leave"
+	cmd.HideOutputFlag = true
+	cmd.Action = func(ctx context.Context, cmd *Command) error {
+		return p.Shell(ctx)
+	}
+	return cmd
+}
+
+// Shell 启动交互式命令行（REPL）
+//
+// 每一行按 shell 规则拆分后，通过与 RunContext 相同的路由执行，每次执行都使用全新的标志状态。
+// 标准输入为终端时支持行编辑、历史记录（保存在 ShellHistoryFile）和基于命令定义的 Tab 补全；
+// 执行命令期间按 Ctrl+C 只会取消当前命令。输入 exit、quit 或按 Ctrl+D 退出。
+func (p *Program) Shell(ctx context.Context) error {
+	if ctx.Value(shellKey{}) != nil {
+		return errors.New("already running in a shell")
+	}
+	ctx = context.WithValue(ctx, shellKey{}, true)
+
+	history := p.loadShellHistory()
+	read := p.shellReader(history)
+
+	for {
+		line, err := read(p.shellPrompt())
+		if errors.Is(err, errInterrupted) {
+			continue
+		}
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		args, err := splitArgs(line)
+		if err != nil {
+			p.printError("", "shell: %v", err)
+			continue
+		}
+		if args[0] == "exit" || args[0] == "quit" {
+			return nil
+		}
+
+		history.add(line)
+		if err := p.runShellLine(ctx, args); err != nil {
+			p.PrintError(err)
+		}
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+	}
+}
+
+// shellPrompt 获取命令行提示符
+func (p *Program) shellPrompt() string {
+	if p.ShellPrompt != "" {
+		return p.ShellPrompt
+	}
+	return p.Name + "> "
+}
+
+// shellReader 根据标准输入类型选择读取方式
+//
+// 终端使用行编辑器（*os.File 会切换到逐字符模式），其它输入逐行读取。
+func (p *Program) shellReader(history *shellHistory) func(prompt string) (string, error) {
+	in := p.Stdin()
+	if !isTerminal(in) {
+		pr := &Prompter{In: in, Out: io.Discard}
+		return func(string) (string, error) {
+			return pr.readLine()
+		}
+	}
+
+	editor := &lineEditor{in: in, out: p.Stderr(), complete: p.completeShell}
+	return func(prompt string) (string, error) {
+		if f, ok := in.(*os.File); ok {
+			restore, err := makeRaw(f.Fd())
+			if err != nil {
+				// 无法切换终端模式时退化为逐行读取
+				_, _ = io.WriteString(p.Stderr(), prompt)
+				return (&Prompter{In: in, Out: io.Discard}).readLine()
+			}
+			defer restore()
+		}
+		editor.history = history.lines
+		return editor.readLine(prompt)
+	}
+}
+
+// runShellLine 执行一行命令，期间 Ctrl+C 只取消这一行
+func (p *Program) runShellLine(ctx context.Context, args []string) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// 启用 HandleSignals 时由 runWithSignals 转发 SIGINT，避免整个命令行被关闭
+	if h, ok := ctx.Value(interruptKey{}).(*interruptHandlers); ok {
+		defer h.push(func(sig os.Signal) {
+			cancel(&SignalError{Signal: sig})
+		})()
+		return p.runAudited(ctx, append([]string{p.Name}, args...), p.run)
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	p.notify(sigCh, os.Interrupt)
+	defer signal.Stop(sigCh)
+	go func() {
+		select {
+		case sig := <-sigCh:
+			cancel(&SignalError{Signal: sig})
+		case <-ctx.Done():
+		}
+	}()
+
+	return p.runAudited(ctx, append([]string{p.Name}, args...), p.run)
+}
+
+// completeShell 根据命令定义返回补全候选
+//
+// 第一个单词补全命令名称（help 之后也补全命令名称），以 - 开头的单词补全该命令的标志。
+func (p *Program) completeShell(before string) []string {
+	words, err := splitArgs(before)
+	if err != nil {
+		return nil
+	}
+	current := ""
+	if len(words) > 0 && !strings.HasSuffix(before, " ") && !strings.HasSuffix(before, "\t") {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	var candidates []string
+	switch {
+	case len(words) == 0 || (len(words) == 1 && words[0] == "help" && !isFlag(current)):
+		candidates = p.commandNames()
+		if len(words) == 0 {
+			candidates = append(candidates, "exit", "quit")
+		}
+	case isFlag(current):
+		candidates = p.flagNames(words[0], strings.HasPrefix(current, "--"))
+	}
+
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, current) && !slices.Contains(matches, c) {
+			matches = append(matches, c)
+		}
+	}
+	slices.Sort(matches)
+	return matches
+}
+
+// commandNames 返回所有可执行的命令名称（含内置命令、别名和插件）
+func (p *Program) commandNames() []string {
+	var names []string
+	for _, cmd := range p.Commands {
+		names = append(names, cmd.Name)
+	}
+	for _, name := range []string{"help", "version", "shell", "run-script", "update"} {
+		if p.get(name) != nil {
+			names = append(names, name)
+		}
+	}
+	if aliases, err := p.aliases(); err == nil {
+		for name := range aliases {
+			names = append(names, name)
+		}
+	}
+	for name := range p.plugins() {
+		names = append(names, name)
+	}
+	return names
+}
+
+// flagNames 返回命令的标志名称（含内置标志和全局标志）
+func (p *Program) flagNames(cmdName string, long bool) []string {
+	prefix := "-"
+	if long {
+		prefix = "--"
+	}
+
+	var names []string
+	if cmd := p.get(cmdName); cmd != nil {
+		_ = p.invoke(cmd, func(inv *Command) error {
+			inv.setupFlags()
+			inv.Flags.VisitAll(func(f *flag.Flag) {
+				names = append(names, prefix+f.Name)
+			})
+			return nil
+		})
+	}
+	for _, f := range p.globalFlags() {
+		names = append(names, "--"+f.name)
+	}
+	return names
+}
+
+// shellHistory 命令行历史记录
+type shellHistory struct {
+	path  string
+	lines []string
+}
+
+// add 记录一行历史并追加到历史文件（与上一条相同时忽略）
+func (h *shellHistory) add(line string) {
+	if n := len(h.lines); n > 0 && h.lines[n-1] == line {
+		return
+	}
+	h.lines = append(h.lines, line)
+	if len(h.lines) > maxShellHistory {
+		h.lines = h.lines[len(h.lines)-maxShellHistory:]
+	}
+
+	if h.path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintln(f, line)
+	_ = f.Close()
+}
+
+// shellHistoryFile 获取历史文件路径
+func (p *Program) shellHistoryFile() string {
+	if p.ShellHistoryFile != "" {
+		return p.Path(p.ShellHistoryFile)
+	}
+	dir := p.userCacheDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, p.Name, "shell_history")
+}
+
+// loadShellHistory 从历史文件加载最近的历史记录
+func (p *Program) loadShellHistory() *shellHistory {
+	h := &shellHistory{path: p.shellHistoryFile()}
+	if h.path == "" {
+		return h
+	}
+	f, err := os.Open(h.path)
+	if err != nil {
+		return h
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			h.lines = append(h.lines, line)
+		}
+	}
+	if len(h.lines) > maxShellHistory {
+		h.lines = h.lines[len(h.lines)-maxShellHistory:]
+	}
+	return h
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newShellProgram(t *testing.T, input string) (*Program, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	prog := NewProgram("testapp", "1.0.0")
+	prog.ShellHistoryFile = filepath.Join(t.TempDir(), "history")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	prog.SetStdin(strings.NewReader(input))
+	prog.SetStdout(stdout)
+	prog.SetStderr(stderr)
+
+	var name string
+	greetCmd := NewCommand("greet", "Greet someone")
+	greetCmd.Flags.StringVar(&name, "name", "world", "Name")
+	greetCmd.Action = func(ctx context.Context, cmd *Command) error {
+		fmt.Fprintf(cmd.Stdout(), "hello %s\n", name)
+		return nil
+	}
+	prog.Commands = []*Command{greetCmd}
+	return prog, stdout, stderr
+}
+
+func TestProgram_Shell(t *testing.T) {
+	input := "greet -name 'Ada Lovelace'\n\ngreet\nnope\nexit\ngreet\n"
+	prog, stdout, stderr := newShellProgram(t, input)
+
+	if err := prog.Shell(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 每一行都使用全新的标志状态，exit 之后的行不会执行
+	if stdout.String() != "hello Ada Lovelace\nhello world\n" {
+		t.Errorf("Unexpected output: %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Error: unknown command: nope") {
+		t.Errorf("Expected error for unknown command, got %q", stderr.String())
+	}
+
+	history, err := os.ReadFile(prog.ShellHistoryFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(history) != "greet -name 'Ada Lovelace'\ngreet\nnope\n" {
+		t.Errorf("Unexpected history file: %q", history)
+	}
+}
+
+func TestProgram_ShellEOF(t *testing.T) {
+	prog, stdout, _ := newShellProgram(t, "greet")
+
+	if err := prog.Shell(context.Background()); err != nil {
+		t.Fatalf("Expected no error at EOF, got %v", err)
+	}
+	if stdout.String() != "hello world\n" {
+		t.Errorf("Expected last line without newline to run, got %q", stdout.String())
+	}
+}
+
+func TestProgram_ShellCommand(t *testing.T) {
+	prog, stdout, stderr := newShellProgram(t, "greet -name x\nshell\nquit\n")
+	prog.EnableShell = true
+
+	if err := prog.Run([]string{"testapp", "shell"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stdout.String() != "hello x\n" {
+		t.Errorf("Unexpected output: %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "already running in a shell") {
+		t.Errorf("Expected nested shell to be refused, got %q", stderr.String())
+	}
+}
+
+func TestProgram_ShellTerminal(t *testing.T) {
+	prog, stdout, stderr := newShellProgram(t, "gr\t -na\t bob\r\x1b[A\x7f\x7f\x7fann\r\x04")
+	prog.SetStdin(ttyReader{prog.Stdin()})
+	if err := os.WriteFile(prog.ShellHistoryFile, []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := prog.Shell(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stdout.String() != "hello bob\nhello ann\n" {
+		t.Errorf("Unexpected output: %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "testapp> ") {
+		t.Errorf("Expected prompt on stderr, got %q", stderr.String())
+	}
+}
+
+func TestProgram_CompleteShell(t *testing.T) {
+	prog, _, _ := newShellProgram(t, "")
+	prog.EnableShell = true
+	prog.Commands[0].Timeout = 1
+
+	tests := []struct {
+		before string
+		want   []string
+	}{
+		{"", []string{"exit", "greet", "help", "quit", "shell", "version"}},
+		{"g", []string{"greet"}},
+		{"help ", []string{"greet", "help", "shell", "version"}},
+		{"greet -", []string{"-name", "-o", "-output", "-timeout"}},
+		{"greet --n", []string{"--name"}},
+		{"greet ", nil},
+		{"nope -", nil},
+	}
+
+	for _, tt := range tests {
+		if got := prog.completeShell(tt.before); !slices.Equal(got, tt.want) {
+			t.Errorf("completeShell(%q) = %q, want %q", tt.before, got, tt.want)
+		}
+	}
+}
+
+func TestProgram_ShellInterruptWithHandleSignals(t *testing.T) {
+	prog, stdout, stderr := newShellProgram(t, "block\ngreet\n")
+	prog.EnableShell = true
+	prog.HandleSignals = true
+	exited := false
+	prog.exitFunc = func(int) { exited = true }
+
+	// 与 signal.Notify 一样，信号发送给所有注册的通道
+	var mu sync.Mutex
+	var channels []chan<- os.Signal
+	prog.notifySignals = func(c chan<- os.Signal, sig ...os.Signal) {
+		mu.Lock()
+		defer mu.Unlock()
+		channels = append(channels, c)
+	}
+	blockCmd := NewCommand("block", "Wait for Ctrl+C")
+	blockCmd.Action = func(ctx context.Context, cmd *Command) error {
+		mu.Lock()
+		for _, c := range channels {
+			c <- os.Interrupt
+		}
+		mu.Unlock()
+		<-ctx.Done()
+		return context.Cause(ctx)
+	}
+	prog.Commands = append(prog.Commands, blockCmd)
+
+	if err := prog.Run([]string{"testapp", "shell"}); err != nil {
+		t.Fatalf("Expected shell to survive Ctrl+C, got %v", err)
+	}
+	if exited {
+		t.Error("Expected program not to exit")
+	}
+	if !strings.Contains(stderr.String(), "interrupted by signal") {
+		t.Errorf("Expected interrupted line to be reported, got %q", stderr.String())
+	}
+	if strings.Contains(stderr.String(), "shutting down") {
+		t.Errorf("Expected shell not to shut down, got %q", stderr.String())
+	}
+	if stdout.String() != "hello world\n" {
+		t.Errorf("Expected next line to run, got %q", stdout.String())
+	}
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// DefaultShutdownTimeout 收到中断信号后等待 Action 返回的默认宽限期
+const DefaultShutdownTimeout = 10 * time.Second
+
+// SignalError 表示命令因收到系统信号而被取消
+//
+// 启用 Program.HandleSignals 后，收到 SIGINT/SIGTERM 时 context 会以
+// *SignalError 作为原因（cause）取消，Action 可以通过 context.Cause 获取。
+type SignalError struct {
+	Signal os.Signal // 收到的信号
+}
+
+// Error 实现 error 接口
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("interrupted by signal: %s", e.Signal)
+}
+
+// ExitCode 返回按 shell 惯例计算的退出码（128 + 信号值）
+func (e *SignalError) ExitCode() int {
+	return signalExitCode(e.Signal)
+}
+
+// interruptKey 在 context 中保存 runWithSignals 的 SIGINT 处理链
+type interruptKey struct{}
+
+// interruptHandlers SIGINT 处理链
+//
+// 内层代码（如交互式命令行中正在执行的一行命令）可以临时接管 SIGINT，
+// 此时 runWithSignals 只调用最内层的处理函数，而不会结束整个程序。
+type interruptHandlers struct {
+	mu    sync.Mutex
+	stack []func(os.Signal)
+}
+
+// push 接管 SIGINT，返回恢复函数
+func (h *interruptHandlers) push(fn func(os.Signal)) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stack = append(h.stack, fn)
+	n := len(h.stack)
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.stack = h.stack[:n-1]
+	}
+}
+
+// handle 将信号交给最内层的处理函数，没有处理函数时返回 false
+func (h *interruptHandlers) handle(sig os.Signal) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.stack) == 0 {
+		return false
+	}
+	h.stack[len(h.stack)-1](sig)
+	return true
+}
+
+// shutdownTimeout 获取收到信号后的宽限期
+func (p *Program) shutdownTimeout() time.Duration {
+	if p.ShutdownTimeout > 0 {
+		return p.ShutdownTimeout
+	}
+	return DefaultShutdownTimeout
+}
+
+// runWithSignals 在监听 SIGINT/SIGTERM 的 context 中执行 run
+//
+// 第一次收到信号时以 *SignalError 取消 context，并等待 Action 在宽限期内返回；
+// 超过宽限期或再次收到信号时强制退出进程。SIGINT 被内层接管时（见 interruptHandlers）只交给内层处理。
+func (p *Program) runWithSignals(ctx context.Context, args []string) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	handlers := &interruptHandlers{}
+	ctx = context.WithValue(ctx, interruptKey{}, handlers)
+
+	sigCh := make(chan os.Signal, 2)
+	p.notify(sigCh, shutdownSignals...)
+	defer signal.Stop(sigCh)
+
+	done := make(chan error, 1)
+	go func() {
+		// 命令在单独的 goroutine 中执行，panic 不会经过调用方的 defer
+		defer auditTrailFrom(ctx).finishPanic()
+		done <- p.run(ctx, args)
+	}()
+
+	var sig os.Signal
+	for sig == nil {
+		select {
+		case err := <-done:
+			return err
+		case s := <-sigCh:
+			if s != os.Interrupt || !handlers.handle(s) {
+				sig = s
+			}
+		}
+	}
+
+	cause := &SignalError{Signal: sig}
+	cancel(cause)
+	_, _ = fmt.Fprintln(p.Stderr())
+	p.printWarning("Received %s, shutting down (press Ctrl+C again to force)", sig)
+
+	timer := time.NewTimer(p.shutdownTimeout())
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		// Action 正常清理并返回时，用信号原因代替 nil 或 context.Canceled，
+		// 以便调用方得到正确的退出码
+		if err == nil || errors.Is(err, context.Canceled) {
+			return cause
+		}
+		return err
+	case <-timer.C:
+		p.printError("", "Shutdown timed out after %s, forcing exit", p.shutdownTimeout())
+	case <-sigCh:
+		p.printError("", "Forced exit")
+	}
+
+	// 进程即将退出，不会再回到 runAudited
+	auditTrailFrom(ctx).finish(cause)
+	p.exit(cause.ExitCode())
+	return cause
+}
+
+// SetSignalNotify 设置注册信号通知的函数（默认 signal.Notify）
+//
+// 测试时可以替换为不接收真实信号的实现，避免测试进程的信号被程序拦截。
+func (p *Program) SetSignalNotify(notify func(c chan<- os.Signal, sig ...os.Signal)) {
+	p.notifySignals = notify
+}
+
+// SetExitFunc 设置强制退出时调用的函数（默认 os.Exit）
+//
+// 测试时可以替换以免结束测试进程；函数返回后 RunContext 返回导致退出的错误。
+func (p *Program) SetExitFunc(exit func(code int)) {
+	p.exitFunc = exit
+}
+
+// notify 注册信号通知（测试时可替换）
+func (p *Program) notify(c chan<- os.Signal, sig ...os.Signal) {
+	if p.notifySignals != nil {
+		p.notifySignals(c, sig...)
+		return
+	}
+	signal.Notify(c, sig...)
+}
+
+// exit 退出进程（测试时可替换）
+func (p *Program) exit(code int) {
+	if p.exitFunc != nil {
+		p.exitFunc(code)
+		return
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// shutdownSignals 触发优雅退出的信号（Plan 9 只有中断 note）
+var shutdownSignals = []os.Signal{os.Interrupt}
+
+// signalExitCode 返回信号对应的退出码（Plan 9 上没有信号编号，固定为 1）
+func signalExitCode(sig os.Signal) int {
+	return 1
+}
+
+// exitSignal 返回导致进程退出的信号（Plan 9 上无法从退出状态得到，总是返回 nil）
+func exitSignal(err *exec.ExitError) os.Signal {
+	return nil
+}
+
+// isBrokenPipe 判断写入错误是否由读取端关闭管道引起
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, io.ErrClosedPipe) || errors.Is(err, os.ErrClosed)
+}
@@ -0,0 +1,35 @@
+//go:build !plan9
+
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// shutdownSignals 触发优雅退出的信号
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// signalExitCode 返回按 shell 惯例计算的退出码（128 + 信号值），无法计算时返回 1
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
+
+// exitSignal 返回导致进程退出的信号，进程正常退出时返回 nil
+func exitSignal(err *exec.ExitError) os.Signal {
+	if ws, ok := err.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return ws.Signal()
+	}
+	return nil
+}
+
+// isBrokenPipe 判断写入错误是否由读取端关闭管道引起
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrClosedPipe)
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeSignals 替换 Program 的信号注册函数，返回用于发送信号的通道
+func fakeSignals(prog *Program) <-chan chan<- os.Signal {
+	registered := make(chan chan<- os.Signal, 1)
+	prog.notifySignals = func(c chan<- os.Signal, sig ...os.Signal) {
+		registered <- c
+	}
+	return registered
+}
+
+func TestProgram_HandleSignals_CancelsContext(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.HandleSignals = true
+	prog.SetOutput(&bytes.Buffer{})
+	registered := fakeSignals(prog)
+
+	var cause error
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		(<-registered) <- os.Interrupt
+		<-ctx.Done()
+		cause = context.Cause(ctx)
+		return ctx.Err()
+	}
+	prog.Commands = []*Command{testCmd}
+
+	err := prog.Run([]string{"testapp", "test"})
+
+	var sigErr *SignalError
+	if !errors.As(cause, &sigErr) {
+		t.Fatalf("Expected context cause to be *SignalError, got %v", cause)
+	}
+	if sigErr.Signal != os.Interrupt {
+		t.Errorf("Expected signal to be os.Interrupt, got %v", sigErr.Signal)
+	}
+	if !errors.As(err, &sigErr) {
+		t.Errorf("Expected Run to return *SignalError, got %v", err)
+	}
+	if sigErr.ExitCode() != 130 {
+		t.Errorf("Expected exit code 130, got %d", sigErr.ExitCode())
+	}
+}
+
+func TestProgram_HandleSignals_ActionError(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.HandleSignals = true
+	prog.SetOutput(&bytes.Buffer{})
+	registered := fakeSignals(prog)
+
+	expectedErr := errors.New("cleanup failed")
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		(<-registered) <- syscall.SIGTERM
+		<-ctx.Done()
+		return expectedErr
+	}
+	prog.Commands = []*Command{testCmd}
+
+	err := prog.Run([]string{"testapp", "test"})
+	if err != expectedErr {
+		t.Errorf("Expected error to be %v, got %v", expectedErr, err)
+	}
+}
+
+func TestProgram_HandleSignals_GracePeriodExpires(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.HandleSignals = true
+	prog.ShutdownTimeout = 10 * time.Millisecond
+	prog.SetOutput(&bytes.Buffer{})
+	registered := fakeSignals(prog)
+
+	exitCode := -1
+	prog.exitFunc = func(code int) { exitCode = code }
+
+	release := make(chan struct{})
+	defer close(release)
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		(<-registered) <- os.Interrupt
+		<-release // 忽略取消，模拟卡住的 Action
+		return nil
+	}
+	prog.Commands = []*Command{testCmd}
+
+	err := prog.Run([]string{"testapp", "test"})
+	if exitCode != 130 {
+		t.Errorf("Expected forced exit with code 130, got %d", exitCode)
+	}
+	var sigErr *SignalError
+	if !errors.As(err, &sigErr) {
+		t.Errorf("Expected *SignalError, got %v", err)
+	}
+}
+
+func TestProgram_HandleSignals_SecondSignalForcesExit(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.HandleSignals = true
+	prog.ShutdownTimeout = time.Hour
+	prog.SetOutput(&bytes.Buffer{})
+	registered := fakeSignals(prog)
+
+	exitCode := -1
+	prog.exitFunc = func(code int) { exitCode = code }
+
+	release := make(chan struct{})
+	defer close(release)
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		c := <-registered
+		c <- os.Interrupt
+		<-ctx.Done()
+		c <- os.Interrupt
+		<-release
+		return nil
+	}
+	prog.Commands = []*Command{testCmd}
+
+	_ = prog.Run([]string{"testapp", "test"})
+	if exitCode != 130 {
+		t.Errorf("Expected forced exit with code 130, got %d", exitCode)
+	}
+}
+
+func TestProgram_HandleSignals_NoSignal(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.HandleSignals = true
+	prog.SetOutput(&bytes.Buffer{})
+	fakeSignals(prog)
+
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		return nil
+	}
+	prog.Commands = []*Command{testCmd}
+
+	if err := prog.Run([]string{"testapp", "test"}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// errUnterminatedQuote 引号未闭合
+var errUnterminatedQuote = errors.New("unterminated quote")
+
+// splitOptions 拆分参数时的可选行为
+type splitOptions struct {
+	comments bool                // 单词开头的 # 及其后的内容视为注释
+	getenv   func(string) string // 非 nil 时展开引号外和双引号内的 $NAME、${NAME}
+}
+
+// splitArgs 按 shell 规则将一行文本拆分为参数
+//
+// 支持空白分隔、单引号（内容原样保留）、双引号（支持 \" \\ \$ \` 转义）
+// 以及引号外的反斜杠转义。不做变量展开和通配符展开。
+func splitArgs(line string) ([]string, error) {
+	return splitArgsWith(line, splitOptions{})
+}
+
+// splitArgsWith 按 shell 规则拆分参数，并按 opts 处理注释和变量展开
+//
+// 展开后的值不会再次拆分，即使其中包含空白。
+func splitArgsWith(line string, opts splitOptions) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		case r == '#' && !inArg && opts.comments:
+			return args, nil
+		case r == '\'':
+			inArg = true
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, errUnterminatedQuote
+			}
+			cur.WriteString(string(runes[i+1 : end]))
+			i = end
+		case r == '"':
+			inArg = true
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				} else if runes[i] == '$' && opts.getenv != nil {
+					if end, ok := expandVar(&cur, runes, i, opts.getenv); ok {
+						i = end
+						continue
+					}
+				}
+				cur.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, errUnterminatedQuote
+			}
+		case r == '\\':
+			inArg = true
+			if i+1 < len(runes) {
+				i++
+				cur.WriteRune(runes[i])
+			}
+		case r == '$' && opts.getenv != nil:
+			inArg = true
+			if end, ok := expandVar(&cur, runes, i, opts.getenv); ok {
+				i = end
+			} else {
+				cur.WriteRune(r)
+			}
+		default:
+			inArg = true
+			cur.WriteRune(r)
+		}
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// expandVar 展开 runes[i] 处的 $NAME 或 ${NAME}，返回变量引用最后一个字符的位置
+//
+// $ 之后不是合法的变量名时返回 false，由调用方按普通字符处理。
+func expandVar(b *strings.Builder, runes []rune, i int, getenv func(string) string) (int, bool) {
+	if i+1 < len(runes) && runes[i+1] == '{' {
+		end := indexRune(runes, i+2, '}')
+		if end < 0 || end == i+2 {
+			return 0, false
+		}
+		b.WriteString(getenv(string(runes[i+2 : end])))
+		return end, true
+	}
+
+	end := i + 1
+	for end < len(runes) && (runes[end] == '_' || unicode.IsLetter(runes[end]) || (end > i+1 && unicode.IsDigit(runes[end]))) {
+		end++
+	}
+	if end == i+1 {
+		return 0, false
+	}
+	b.WriteString(getenv(string(runes[i+1 : end])))
+	return end - 1, true
+}
+
+// quoteArg 在需要时为参数加上单引号，使其能被 splitArgs 还原
+func quoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\r'\"\\$`#") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// indexRune 从 start 开始查找 r 的位置，未找到返回 -1
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
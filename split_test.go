@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"deploy --env prod", []string{"deploy", "--env", "prod"}},
+		{"  a\tb  ", []string{"a", "b"}},
+		{`echo 'hello world'`, []string{"echo", "hello world"}},
+		{`echo "say \"hi\" \$HOME"`, []string{"echo", `say "hi" $HOME`}},
+		{`echo "a\nb"`, []string{"echo", `a\nb`}},
+		{`echo hello\ world`, []string{"echo", "hello world"}},
+		{`echo ''`, []string{"echo", ""}},
+		{`--msg=it's ok'`, []string{"--msg=its ok"}},
+		{`名字 "你好 世界"`, []string{"名字", "你好 世界"}},
+	}
+
+	for _, tt := range tests {
+		got, err := splitArgs(tt.line)
+		if err != nil {
+			t.Errorf("splitArgs(%q) failed: %v", tt.line, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestSplitArgsUnterminated(t *testing.T) {
+	for _, line := range []string{`echo 'abc`, `echo "abc`} {
+		if _, err := splitArgs(line); err == nil {
+			t.Errorf("Expected error for %q", line)
+		}
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	for _, arg := range []string{"plain", "", "with space", "it's", `back\slash`, `"quoted"`} {
+		got, err := splitArgs(quoteArg(arg))
+		if err != nil || len(got) != 1 || got[0] != arg {
+			t.Errorf("quoteArg(%q) did not round-trip: %q (%v)", arg, got, err)
+		}
+	}
+}
+
+func TestSplitArgsWith(t *testing.T) {
+	env := map[string]string{"NAME": "Ada Lovelace", "ENV": "prod", "_X1": "x"}
+	opts := splitOptions{comments: true, getenv: func(k string) string { return env[k] }}
+
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"# only a comment", nil},
+		{"deploy --env $ENV # trailing", []string{"deploy", "--env", "prod"}},
+		{"greet $NAME", []string{"greet", "Ada Lovelace"}},
+		{`greet "Hi, ${NAME}!"`, []string{"greet", "Hi, Ada Lovelace!"}},
+		{`greet '$NAME'`, []string{"greet", "$NAME"}},
+		{`greet \$NAME "\$ENV"`, []string{"greet", "$NAME", "$ENV"}},
+		{"echo $_X1-$MISSING.", []string{"echo", "x-."}},
+		{"echo $ 5$ ${}", []string{"echo", "$", "5$", "${}"}},
+		{"echo a#b", []string{"echo", "a#b"}},
+		{"echo '#' \"#\"", []string{"echo", "#", "#"}},
+	}
+
+	for _, tt := range tests {
+		got, err := splitArgsWith(tt.line, opts)
+		if err != nil {
+			t.Errorf("splitArgsWith(%q) failed: %v", tt.line, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitArgsWith(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	// 未启用时 $ 和 # 按普通字符处理
+	got, _ := splitArgs("echo $ENV #x")
+	if !slices.Equal(got, []string{"echo", "$ENV", "#x"}) {
+		t.Errorf("Expected no expansion by default, got %q", got)
+	}
+}
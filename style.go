@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// 颜色模式（--color 标志的值）
+const (
+	ColorAuto   = "auto"   // 输出为终端时使用颜色（默认）
+	ColorAlways = "always" // 总是使用颜色
+	ColorNever  = "never"  // 从不使用颜色
+)
+
+// Style ANSI SGR 样式参数（如 "1" 粗体、"36" 青色、"1;31" 粗体红色），空字符串表示不加样式
+type Style string
+
+// Render 为文本加上样式，样式为空时原样返回
+func (s Style) Render(text string) string {
+	if s == "" || text == "" {
+		return text
+	}
+	return "\x1b[" + string(s) + "m" + text + "\x1b[0m"
+}
+
+// Theme 帮助和错误信息使用的样式
+type Theme struct {
+	Heading Style // 标题（USAGE:、COMMANDS:、Options: 等）
+	Command Style // 命令名称
+	Flag    Style // 标志名称
+	Error   Style // 错误信息
+	Warning Style // 警告信息
+}
+
+// DefaultTheme 默认的样式
+var DefaultTheme = Theme{
+	Heading: "1",
+	Command: "36",
+	Flag:    "33",
+	Error:   "31",
+	Warning: "33",
+}
+
+// ansiPattern 匹配 ANSI 转义序列（CSI 序列和 OSC 序列）
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)`)
+
+// StripANSI 移除文本中的 ANSI 转义序列
+func StripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// parseColorMode 校验颜色模式，空字符串视为 auto
+func parseColorMode(mode string) (string, error) {
+	switch mode {
+	case "", ColorAuto:
+		return ColorAuto, nil
+	case ColorAlways, ColorNever:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("must be %s, %s or %s", ColorAuto, ColorAlways, ColorNever)
+	}
+}
+
+// colorEnabled 判断写入 w 时是否使用颜色
+//
+// --color 标志优先；auto 模式下 NO_COLOR 禁用颜色，FORCE_COLOR 强制使用颜色，
+// 否则仅在 w 为终端且 TERM 不是 dumb 时使用颜色。
+func (e *processEnv) colorEnabled(w io.Writer, mode string) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if e.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if v := e.Getenv("FORCE_COLOR"); v != "" && v != "0" && v != "false" {
+		return true
+	}
+	return isTerminal(w) && e.Getenv("TERM") != "dumb"
+}
+
+// styles 返回写入 w 时使用的样式，未设置主题或不使用颜色时返回空样式
+func (e *processEnv) styles(theme *Theme, w io.Writer, mode string) Theme {
+	if theme == nil || !e.colorEnabled(w, mode) {
+		return Theme{}
+	}
+	return *theme
+}
+
+// Styles 返回写入 w 时使用的样式
+//
+// 未设置 Program.Theme、指定了 --color=never、设置了 NO_COLOR 或 w 不是终端时返回空样式，
+// 此时 Style.Render 原样返回文本，因此写入缓冲区或管道的输出不含 ANSI 转义序列。
+func (c *Command) Styles(w io.Writer) Theme {
+	return c.styles(c.theme, w, c.colorMode)
+}
+
+// colorModeArg 从参数中查找 --color 标志的值（"--" 之后的参数不处理）
+//
+// 用于在确定命令之前输出的帮助和错误信息。
+func colorModeArg(args []string) string {
+	mode, _ := flagArg(args, "color", true)
+	return mode
+}
+
+// styleFlagDefaults 为 flag.PrintDefaults 输出中的标志名称加上样式
+func styleFlagDefaults(defaults string, style Style) string {
+	if style == "" {
+		return defaults
+	}
+	lines := strings.SplitAfter(defaults, "\n")
+	for i, line := range lines {
+		rest, ok := strings.CutPrefix(line, "  -")
+		if !ok {
+			continue
+		}
+		end := strings.IndexAny(rest, " \t\n")
+		if end < 0 {
+			end = len(rest)
+		}
+		lines[i] = "  " + style.Render("-"+rest[:end]) + rest[end:]
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStyle_Render(t *testing.T) {
+	if got := Style("1;31").Render("boom"); got != "\x1b[1;31mboom\x1b[0m" {
+		t.Errorf("Unexpected styled text: %q", got)
+	}
+	if got := Style("").Render("plain"); got != "plain" {
+		t.Errorf("Expected empty style to return text unchanged, got %q", got)
+	}
+	if got := Style("1").Render(""); got != "" {
+		t.Errorf("Expected empty text to stay empty, got %q", got)
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	tests := map[string]string{
+		"plain":                  "plain",
+		"\x1b[1;31mboom\x1b[0m!": "boom!",
+		"\x1b[2K\x1b[1Aline":     "line",
+		"\x1b]8;;https://x.y\x07link\x1b]8;;\x07": "link",
+	}
+	for in, want := range tests {
+		if got := StripANSI(in); got != want {
+			t.Errorf("StripANSI(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	tty := ttyWriter{&bytes.Buffer{}}
+	buf := &bytes.Buffer{}
+
+	tests := []struct {
+		name string
+		env  []string
+		w    any
+		mode string
+		want bool
+	}{
+		{"tty", []string{"TERM=xterm"}, tty, "", true},
+		{"buffer", []string{"TERM=xterm"}, buf, ColorAuto, false},
+		{"dumb terminal", []string{"TERM=dumb"}, tty, "", false},
+		{"NO_COLOR", []string{"NO_COLOR=1"}, tty, "", false},
+		{"FORCE_COLOR", []string{"FORCE_COLOR=1"}, buf, "", true},
+		{"FORCE_COLOR=0", []string{"FORCE_COLOR=0"}, buf, "", false},
+		{"NO_COLOR wins", []string{"NO_COLOR=1", "FORCE_COLOR=1"}, tty, "", false},
+		{"always", []string{"NO_COLOR=1"}, buf, ColorAlways, true},
+		{"never", []string{"FORCE_COLOR=1"}, tty, ColorNever, false},
+	}
+	for _, tt := range tests {
+		var e processEnv
+		e.SetEnviron(tt.env)
+		w := tt.w.(interface{ Write([]byte) (int, error) })
+		if got := e.colorEnabled(w, tt.mode); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	if _, err := parseColorMode("sometimes"); err == nil {
+		t.Error("Expected invalid color mode to be rejected")
+	}
+}
+
+func newThemedProgram() (*Program, *bytes.Buffer) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.Theme = &DefaultTheme
+	prog.SetEnviron(nil)
+
+	var name string
+	cmd := NewCommand("greet", "Greet someone")
+	cmd.Flags.StringVar(&name, "name", "world", "Name to greet")
+	cmd.Action = func(ctx context.Context, cmd *Command) error {
+		st := cmd.Styles(cmd.Stdout())
+		_, err := cmd.Stdout().Write([]byte(st.Command.Render("hello " + name)))
+		return err
+	}
+	prog.Commands = []*Command{cmd}
+
+	buf := &bytes.Buffer{}
+	prog.SetOutput(buf)
+	return prog, buf
+}
+
+func TestTheme_ProgramUsage(t *testing.T) {
+	prog, buf := newThemedProgram()
+
+	if err := prog.Run([]string{"testapp", "--color=never"}); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.String()
+	if strings.Contains(plain, "\x1b[") {
+		t.Errorf("Expected no ANSI codes with --color=never, got %q", plain)
+	}
+	if !strings.Contains(plain, "--color when") {
+		t.Errorf("Expected --color in global options, got:\n%s", plain)
+	}
+
+	buf.Reset()
+	if err := prog.Run([]string{"testapp", "--color", "always"}); err != nil {
+		t.Fatal(err)
+	}
+	styled := buf.String()
+	for _, want := range []string{"\x1b[1mUSAGE:\x1b[0m", "\x1b[36mgreet\x1b[0m", "\x1b[33m--color when\x1b[0m"} {
+		if !strings.Contains(styled, want) {
+			t.Errorf("Expected %q in styled usage, got %q", want, styled)
+		}
+	}
+	if StripANSI(styled) != plain {
+		t.Errorf("Expected styled usage to match plain usage after stripping:\n%s\n---\n%s", StripANSI(styled), plain)
+	}
+
+	// 写入缓冲区时默认不使用颜色
+	buf.Reset()
+	if err := prog.Run([]string{"testapp"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != plain {
+		t.Errorf("Expected automatic mode to be plain for a buffer, got %q", buf.String())
+	}
+}
+
+func TestTheme_CommandUsage(t *testing.T) {
+	prog, buf := newThemedProgram()
+
+	if err := prog.Run([]string{"testapp", "help", "greet"}); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.String()
+
+	buf.Reset()
+	if err := prog.Run([]string{"testapp", "--color=always", "help", "greet"}); err != nil {
+		t.Fatal(err)
+	}
+	styled := buf.String()
+	for _, want := range []string{"\x1b[1mUsage:\x1b[0m", "\x1b[36mgreet\x1b[0m", "\x1b[1mOptions:\x1b[0m", "  \x1b[33m-name\x1b[0m string"} {
+		if !strings.Contains(styled, want) {
+			t.Errorf("Expected %q in styled usage, got %q", want, styled)
+		}
+	}
+	if StripANSI(styled) != plain {
+		t.Errorf("Expected styled usage to match plain usage after stripping:\n%s\n---\n%s", StripANSI(styled), plain)
+	}
+}
+
+func TestTheme_Errors(t *testing.T) {
+	prog, buf := newThemedProgram()
+	prog.SetEnviron([]string{"FORCE_COLOR=1"})
+
+	_ = prog.Run([]string{"testapp", "nope"})
+	if !strings.HasPrefix(buf.String(), "\x1b[31mUnknown command: nope\x1b[0m\n\n") {
+		t.Errorf("Expected styled error, got %q", buf.String())
+	}
+
+	buf.Reset()
+	prog.SetEnviron([]string{"FORCE_COLOR=1", "NO_COLOR=1"})
+	prog.PrintError(context.Canceled)
+	if buf.String() != "Error: context canceled\n" {
+		t.Errorf("Expected NO_COLOR to disable styling, got %q", buf.String())
+	}
+}
+
+func TestCommand_Styles(t *testing.T) {
+	prog, buf := newThemedProgram()
+
+	if err := prog.Run([]string{"testapp", "greet", "--color=always"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "\x1b[36mhello world\x1b[0m" {
+		t.Errorf("Expected styled output, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := prog.Run([]string{"testapp", "greet"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "hello world" {
+		t.Errorf("Expected the --color value not to leak between runs, got %q", buf.String())
+	}
+
+	if err := prog.Run([]string{"testapp", "greet", "--color=sometimes"}); err == nil || !strings.Contains(err.Error(), "must be auto, always or never") {
+		t.Errorf("Expected invalid --color error, got %v", err)
+	}
+
+	prog.Theme = nil
+	prog.SetEnviron([]string{"FORCE_COLOR=1"})
+	buf.Reset()
+	if err := prog.Run([]string{"testapp", "greet"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "hello world" {
+		t.Errorf("Expected no styling without a theme, got %q", buf.String())
+	}
+}
@@ -0,0 +1,363 @@
+package cli
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Table 表格数据
+//
+// 直接传给 Command.Print 可以完全控制列的顺序和内容；
+// JSON 输出时每一行会被编码为以表头为键的对象。
+type Table struct {
+	Header []string   // 表头
+	Rows   [][]string // 数据行
+}
+
+// MarshalJSON 将表格编码为对象数组，键的顺序与表头一致
+func (t *Table) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.records())
+}
+
+// records 将每一行转换为有序的 JSON 对象
+func (t *Table) records() []tableRecord {
+	records := make([]tableRecord, len(t.Rows))
+	for i, row := range t.Rows {
+		records[i] = tableRecord{header: t.Header, row: row}
+	}
+	return records
+}
+
+// tableRecord 按表头顺序编码的一行数据
+type tableRecord struct {
+	header []string
+	row    []string
+}
+
+// MarshalJSON 实现 json.Marshaler 接口
+func (r tableRecord) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range r.header {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		b.Write(k)
+		b.WriteByte(':')
+		value := ""
+		if i < len(r.row) {
+			value = r.row[i]
+		}
+		v, _ := json.Marshal(value)
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// write 以对齐的列写出表格，列宽按终端显示宽度计算（中日韩字符占两列）
+func (t *Table) write(w io.Writer) error {
+	widths := make([]int, len(t.Header))
+	grow := func(row []string) {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+	grow(t.Header)
+	for _, row := range t.Rows {
+		grow(row)
+	}
+
+	var b []byte
+	appendRow := func(row []string) {
+		for i, cell := range row {
+			b = append(b, cell...)
+			if i < len(row)-1 {
+				pad := widths[i] - displayWidth(cell) + 3
+				b = append(b, strings.Repeat(" ", pad)...)
+			}
+		}
+		b = append(b, '\n')
+	}
+	if len(t.Header) > 0 {
+		appendRow(t.Header)
+	}
+	for _, row := range t.Rows {
+		appendRow(row)
+	}
+
+	_, err := w.Write(b)
+	return err
+}
+
+// asTable 判断 v 是否为 Table
+func asTable(v any) (*Table, bool) {
+	switch t := v.(type) {
+	case *Table:
+		return t, t != nil
+	case Table:
+		return &t, true
+	}
+	return nil, false
+}
+
+// tabulate 将任意值转换为表格
+//
+//   - 结构体切片：每个元素一行，列为导出字段
+//   - map 切片：每个元素一行，列为所有键的并集（按字母排序）
+//   - 结构体：单行
+//   - map：KEY、VALUE 两列，按键排序
+//   - 基本类型或其切片：每个值一行，不输出表头
+func tabulate(v any) *Table {
+	if t, ok := asTable(v); ok {
+		return t
+	}
+
+	rv := indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return &Table{}
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			break // []byte 作为单个值
+		}
+		elems := make([]reflect.Value, rv.Len())
+		for i := range elems {
+			elems[i] = indirect(rv.Index(i))
+		}
+		return tabulateList(rv.Type().Elem(), elems)
+	case reflect.Struct:
+		if !isScalar(rv) {
+			return tabulateList(rv.Type(), []reflect.Value{rv})
+		}
+	case reflect.Map:
+		t := &Table{Header: []string{"KEY", "VALUE"}}
+		for _, key := range sortedKeys(rv) {
+			t.Rows = append(t.Rows, []string{formatCell(key), formatCell(rv.MapIndex(key))})
+		}
+		return t
+	}
+
+	return &Table{Rows: [][]string{{formatCell(rv)}}}
+}
+
+// tabulateList 将同类元素列表转换为表格
+func tabulateList(elemType reflect.Type, elems []reflect.Value) *Table {
+	for elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+
+	t := &Table{}
+	switch {
+	case elemType.Kind() == reflect.Struct && !isScalarType(elemType):
+		fields := tableFields(elemType)
+		for _, f := range fields {
+			t.Header = append(t.Header, f.name)
+		}
+		for _, elem := range elems {
+			row := make([]string, len(fields))
+			if elem.IsValid() {
+				for i, f := range fields {
+					fv, err := elem.FieldByIndexErr(f.index)
+					if err == nil {
+						row[i] = formatCell(fv)
+					}
+				}
+			}
+			t.Rows = append(t.Rows, row)
+		}
+	case elemType.Kind() == reflect.Map || elemType.Kind() == reflect.Interface && allMaps(elems):
+		var keys []string
+		for _, elem := range elems {
+			if elem.Kind() != reflect.Map {
+				continue
+			}
+			for _, key := range elem.MapKeys() {
+				if k := formatCell(key); !slices.Contains(keys, k) {
+					keys = append(keys, k)
+				}
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			t.Header = append(t.Header, strings.ToUpper(k))
+		}
+		for _, elem := range elems {
+			row := make([]string, len(keys))
+			if elem.Kind() == reflect.Map {
+				for _, key := range elem.MapKeys() {
+					i := slices.Index(keys, formatCell(key))
+					row[i] = formatCell(elem.MapIndex(key))
+				}
+			}
+			t.Rows = append(t.Rows, row)
+		}
+	default:
+		for _, elem := range elems {
+			t.Rows = append(t.Rows, []string{formatCell(elem)})
+		}
+	}
+	return t
+}
+
+// tableField 表格中的一列
+type tableField struct {
+	name  string
+	index []int
+}
+
+// tableFields 获取结构体的列定义（导出字段，列名取自 json 标签）
+func tableFields(t reflect.Type) []tableField {
+	var fields []tableField
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous && indirectType(f.Type).Kind() == reflect.Struct {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		fields = append(fields, tableField{name: strings.ToUpper(name), index: f.Index})
+	}
+	return fields
+}
+
+// formatCell 将值格式化为单元格文本
+func formatCell(v reflect.Value) string {
+	v = indirect(v)
+	if !v.IsValid() {
+		return ""
+	}
+	if !v.CanInterface() {
+		return fmt.Sprint(v)
+	}
+
+	switch x := v.Interface().(type) {
+	case time.Time:
+		return x.Format(time.RFC3339)
+	case fmt.Stringer:
+		return x.String()
+	case encoding.TextMarshaler:
+		if b, err := x.MarshalText(); err == nil {
+			return string(b)
+		}
+	case []byte:
+		return string(x)
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = formatCell(v.Index(i))
+		}
+		return strings.Join(parts, ",")
+	case reflect.String:
+		return strings.ReplaceAll(v.String(), "\n", " ")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// sortedKeys 返回按文本排序的 map 键
+func sortedKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return strings.Compare(formatCell(a), formatCell(b))
+	})
+	return keys
+}
+
+// allMaps 判断元素是否都是 map（用于 []any）
+func allMaps(elems []reflect.Value) bool {
+	for _, elem := range elems {
+		if elem.IsValid() && elem.Kind() != reflect.Map {
+			return false
+		}
+	}
+	return len(elems) > 0
+}
+
+// isScalar 判断结构体值是否应作为单个值输出（如 time.Time）
+func isScalar(v reflect.Value) bool {
+	return isScalarType(v.Type())
+}
+
+// isScalarType 判断类型是否应作为单个值输出
+func isScalarType(t reflect.Type) bool {
+	if t.Implements(reflect.TypeFor[fmt.Stringer]()) || t.Implements(reflect.TypeFor[encoding.TextMarshaler]()) {
+		return true
+	}
+	return t == reflect.TypeFor[time.Time]()
+}
+
+// indirect 解开指针和接口，直到得到具体的值
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// indirectType 解开指针类型
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// displayWidth 计算字符串在终端中的显示宽度
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth 计算字符的显示宽度：组合字符为 0，东亚宽字符为 2，其它为 1
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.Is(unicode.Cf, r):
+		return 0
+	case r >= 0x1100 && r <= 0x115F, // 谚文字母
+		r >= 0x2E80 && r <= 0x303E,   // 中日韩部首、符号和标点
+		r >= 0x3041 && r <= 0x33FF,   // 假名、注音、中日韩兼容字符
+		r >= 0x3400 && r <= 0x4DBF,   // 中日韩统一表意文字扩展 A
+		r >= 0x4E00 && r <= 0x9FFF,   // 中日韩统一表意文字
+		r >= 0xA000 && r <= 0xA4CF,   // 彝文
+		r >= 0xAC00 && r <= 0xD7A3,   // 谚文音节
+		r >= 0xF900 && r <= 0xFAFF,   // 中日韩兼容表意文字
+		r >= 0xFE30 && r <= 0xFE4F,   // 中日韩兼容形式
+		r >= 0xFF00 && r <= 0xFF60,   // 全角字符
+		r >= 0xFFE0 && r <= 0xFFE6,   // 全角符号
+		r >= 0x1F300 && r <= 0x1F64F, // 表情符号
+		r >= 0x1F900 && r <= 0x1F9FF, // 补充表情符号
+		r >= 0x20000 && r <= 0x3FFFD: // 中日韩统一表意文字扩展 B 及以后
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"hello", 5},
+		{"你好", 4},
+		{"こんにちは", 10},
+		{"한국", 4},
+		{"ｆｕｌｌ", 8},
+		{"é", 1},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := displayWidth(tt.s); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTable_WriteCJK(t *testing.T) {
+	table := &Table{
+		Header: []string{"NAME", "CITY"},
+		Rows:   [][]string{{"张三", "北京"}, {"bob", "NYC"}},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := table.write(buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "NAME   CITY\n张三   北京\nbob    NYC\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestTabulate(t *testing.T) {
+	type embedded struct {
+		ID int
+	}
+	type row struct {
+		embedded
+		Name    string
+		Tags    []string
+		Created time.Time
+		private int
+	}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		v    any
+		want Table
+	}{
+		{
+			name: "struct slice",
+			v:    []*row{{embedded: embedded{ID: 1}, Name: "a", Tags: []string{"x", "y"}, Created: created}, nil},
+			want: Table{
+				Header: []string{"ID", "NAME", "TAGS", "CREATED"},
+				Rows:   [][]string{{"1", "a", "x,y", "2024-01-02T03:04:05Z"}, {"", "", "", ""}},
+			},
+		},
+		{
+			name: "single struct",
+			v:    row{Name: "b"},
+			want: Table{
+				Header: []string{"ID", "NAME", "TAGS", "CREATED"},
+				Rows:   [][]string{{"0", "b", "", "0001-01-01T00:00:00Z"}},
+			},
+		},
+		{
+			name: "map slice",
+			v:    []any{map[string]any{"b": 2, "a": 1}, map[string]any{"c": true}},
+			want: Table{
+				Header: []string{"A", "B", "C"},
+				Rows:   [][]string{{"1", "2", ""}, {"", "", "true"}},
+			},
+		},
+		{
+			name: "scalars",
+			v:    []string{"one", "two"},
+			want: Table{Rows: [][]string{{"one"}, {"two"}}},
+		},
+		{
+			name: "scalar",
+			v:    42,
+			want: Table{Rows: [][]string{{"42"}}},
+		},
+		{
+			name: "time",
+			v:    created,
+			want: Table{Rows: [][]string{{"2024-01-02T03:04:05Z"}}},
+		},
+		{
+			name: "nil",
+			v:    nil,
+			want: Table{},
+		},
+	}
+
+	for _, tt := range tests {
+		got := tabulate(tt.v)
+		if !equalTables(got, &tt.want) {
+			t.Errorf("%s: tabulate() = %+v, want %+v", tt.name, *got, tt.want)
+		}
+	}
+}
+
+func equalTables(a, b *Table) bool {
+	return slices.Equal(a.Header, b.Header) && slices.EqualFunc(a.Rows, b.Rows, slices.Equal)
+}
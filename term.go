@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"errors"
+	"os"
+)
+
+// errTermUnsupported 当前平台不支持修改终端属性
+var errTermUnsupported = errors.New("terminal control is not supported on this platform")
+
+// Terminal 由能够报告自己是否连接到终端的输入输出流实现
+//
+// 框架根据流是否为终端决定默认行为（如交互式询问、表格输出）。
+// 测试时可以用实现了 Terminal 的 Reader/Writer 模拟终端。
+type Terminal interface {
+	IsTerminal() bool
+}
+
+// isTerminal 判断 v 是否连接到终端
+//
+// 实现了 Terminal 接口的值以其返回值为准；否则只有连接到终端的 *os.File 才返回 true
+// （/dev/null 等其它字符设备不算终端），bytes.Buffer 等内存缓冲区总是返回 false。
+func isTerminal(v any) bool {
+	if t, ok := v.(Terminal); ok {
+		return t.IsTerminal()
+	}
+	f, ok := v.(*os.File)
+	if !ok || f == nil {
+		return false
+	}
+	return isTerminalFile(f)
+}
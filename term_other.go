@@ -0,0 +1,24 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+
+package cli
+
+import "os"
+
+// disableEcho 关闭终端回显，返回恢复函数
+func disableEcho(fd uintptr) (func(), error) {
+	return nil, errTermUnsupported
+}
+
+// makeRaw 将终端切换为逐字符输入模式，返回恢复函数
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errTermUnsupported
+}
+
+// isTerminalFile 判断文件是否为终端（以字符设备近似判断）
+func isTerminalFile(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
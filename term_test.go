@@ -0,0 +1,17 @@
+package cli
+
+import (
+	"os"
+	"testing"
+)
+
+func TestIsTerminal_DevNull(t *testing.T) {
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Errorf("Expected %s not to be a terminal", os.DevNull)
+	}
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// 控制台输入模式（见 SetConsoleMode）
+const (
+	enableProcessedInput = 0x0001
+	enableLineInput      = 0x0002
+	enableEchoInput      = 0x0004
+)
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// setConsoleMode 设置控制台模式
+func setConsoleMode(fd uintptr, mode uint32) error {
+	if r, _, err := procSetConsoleMode.Call(fd, uintptr(mode)); r == 0 {
+		return err
+	}
+	return nil
+}
+
+// updateConsoleMode 修改控制台输入模式，返回恢复函数
+func updateConsoleMode(fd uintptr, clear, set uint32) (func(), error) {
+	var old uint32
+	if err := syscall.GetConsoleMode(syscall.Handle(fd), &old); err != nil {
+		return nil, err
+	}
+	if err := setConsoleMode(fd, old&^clear|set); err != nil {
+		return nil, err
+	}
+	return func() { _ = setConsoleMode(fd, old) }, nil
+}
+
+// disableEcho 关闭控制台回显，返回恢复函数
+func disableEcho(fd uintptr) (func(), error) {
+	return updateConsoleMode(fd, enableEchoInput, enableProcessedInput|enableLineInput)
+}
+
+// makeRaw 将终端切换为逐字符输入模式，返回恢复函数
+//
+// 行编辑器依赖 VT 转义序列，Windows 控制台上暂不支持，命令行模式退化为逐行读取。
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errTermUnsupported
+}
+
+// isTerminalFile 判断文件是否为控制台（NUL 等字符设备不算）
+func isTerminalFile(f *os.File) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(f.Fd()), &mode) == nil
+}
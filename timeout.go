@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutError 表示命令的 Action 超过了允许的执行时间
+//
+// 设置了 Command.Timeout（或 Program.Timeout）时，超时后 context 会以
+// *TimeoutError 作为原因取消，RunContext 也会返回该错误，便于 CI 识别。
+type TimeoutError struct {
+	Command string        // 命令名称
+	Timeout time.Duration // 生效的超时时间
+	Err     error         // Action 在超时后返回的错误（可能为 nil）
+}
+
+// Error 实现 error 接口
+func (e *TimeoutError) Error() string {
+	if e.Err != nil && !errors.Is(e.Err, context.DeadlineExceeded) {
+		return fmt.Sprintf("command %q timed out after %s: %v", e.Command, e.Timeout, e.Err)
+	}
+	return fmt.Sprintf("command %q timed out after %s", e.Command, e.Timeout)
+}
+
+// Unwrap 使 errors.Is(err, context.DeadlineExceeded) 成立，并暴露 Action 的错误
+func (e *TimeoutError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{context.DeadlineExceeded}
+	}
+	return []error{context.DeadlineExceeded, e.Err}
+}
+
+// ExitCode 返回与 GNU timeout 一致的退出码 124
+func (e *TimeoutError) ExitCode() int {
+	return 124
+}
+
+// baseTimeout 获取命令自身或程序全局设置的超时时间
+func (c *Command) baseTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return c.defaultTimeout
+}
+
+// setupTimeoutFlag 注册 -timeout 标志，默认值为命令的超时时间（0 表示不限制）
+//
+// 命令自己定义了 timeout 标志时不做任何处理。
+func (c *Command) setupTimeoutFlag() {
+	if c.Flags.Lookup("timeout") != nil {
+		return
+	}
+	c.Flags.DurationVar(&c.timeoutValue, "timeout", c.baseTimeout(), "Maximum execution time")
+	c.addBuiltinFlag("timeout")
+}
+
+// effectiveTimeout 获取本次执行生效的超时时间（-timeout 标志优先）
+func (c *Command) effectiveTimeout() time.Duration {
+	if c.isBuiltinFlag("timeout") {
+		return c.timeoutValue
+	}
+	return c.baseTimeout()
+}
+
+// runAction 在超时控制下执行 Action
+func (c *Command) runAction(ctx context.Context) error {
+	timeout := c.effectiveTimeout()
+	if timeout <= 0 {
+		return c.Action(ctx, c)
+	}
+
+	cause := &TimeoutError{Command: c.Name, Timeout: timeout}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, cause)
+	defer cancel()
+
+	err := c.Action(ctx, c)
+	if context.Cause(ctx) == cause {
+		cause.Err = err
+		return cause
+	}
+	return err
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommand_Timeout(t *testing.T) {
+	cmd := NewCommand("slow", "Slow command")
+	cmd.Timeout = 10 * time.Millisecond
+	cmd.Action = func(ctx context.Context, c *Command) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	err := cmd.Run([]string{})
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Expected *TimeoutError, got %v", err)
+	}
+	if timeoutErr.Command != "slow" {
+		t.Errorf("Expected command to be 'slow', got '%s'", timeoutErr.Command)
+	}
+	if timeoutErr.Timeout != 10*time.Millisecond {
+		t.Errorf("Expected timeout to be 10ms, got %s", timeoutErr.Timeout)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Expected error to match context.DeadlineExceeded")
+	}
+	if ExitCode(err) != 124 {
+		t.Errorf("Expected exit code 124, got %d", ExitCode(err))
+	}
+}
+
+func TestCommand_TimeoutCause(t *testing.T) {
+	cmd := NewCommand("slow", "Slow command")
+	cmd.Timeout = 10 * time.Millisecond
+	var cause error
+	cmd.Action = func(ctx context.Context, c *Command) error {
+		<-ctx.Done()
+		cause = context.Cause(ctx)
+		return nil
+	}
+
+	_ = cmd.Run([]string{})
+
+	var timeoutErr *TimeoutError
+	if !errors.As(cause, &timeoutErr) {
+		t.Errorf("Expected context cause to be *TimeoutError, got %v", cause)
+	}
+}
+
+func TestCommand_TimeoutNotExceeded(t *testing.T) {
+	cmd := NewCommand("fast", "Fast command")
+	cmd.Timeout = time.Minute
+	var deadline time.Time
+	cmd.Action = func(ctx context.Context, c *Command) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	}
+
+	if err := cmd.Run([]string{}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if deadline.IsZero() {
+		t.Error("Expected context to have a deadline")
+	}
+}
+
+func TestCommand_TimeoutFlagOverride(t *testing.T) {
+	cmd := NewCommand("slow", "Slow command")
+	cmd.Timeout = time.Hour
+	cmd.Action = func(ctx context.Context, c *Command) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	err := cmd.Run([]string{"--timeout", "10ms"})
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Expected *TimeoutError, got %v", err)
+	}
+	if timeoutErr.Timeout != 10*time.Millisecond {
+		t.Errorf("Expected overridden timeout 10ms, got %s", timeoutErr.Timeout)
+	}
+}
+
+func TestCommand_TimeoutFlagInUsage(t *testing.T) {
+	cmd := NewCommand("slow", "Slow command")
+	cmd.Timeout = time.Minute
+	buf := &bytes.Buffer{}
+	cmd.SetOutput(buf)
+
+	if err := cmd.PrintUsage(); err != nil {
+		t.Fatalf("PrintUsage failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "-timeout") {
+		t.Errorf("Expected usage to mention -timeout, got: %s", buf.String())
+	}
+}
+
+func TestCommand_TimeoutUserDefinedFlag(t *testing.T) {
+	var timeout string
+	cmd := NewCommand("slow", "Slow command")
+	cmd.Timeout = time.Minute
+	cmd.Flags.StringVar(&timeout, "timeout", "", "User timeout flag")
+	cmd.Action = func(ctx context.Context, c *Command) error {
+		return nil
+	}
+
+	if err := cmd.Run([]string{"-timeout", "forever"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if timeout != "forever" {
+		t.Errorf("Expected user flag to receive value, got '%s'", timeout)
+	}
+}
+
+func TestCommand_NoTimeoutKeepsContext(t *testing.T) {
+	cmd := NewCommand("test", "Test command")
+	cmd.SetOutput(&bytes.Buffer{})
+	var hasDeadline bool
+	cmd.Action = func(ctx context.Context, cmd *Command) error {
+		_, hasDeadline = ctx.Deadline()
+		return nil
+	}
+
+	if err := cmd.Run(nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if hasDeadline {
+		t.Error("Expected no deadline when no timeout is configured")
+	}
+}
+
+func TestCommand_TimeoutFlagWithoutDefault(t *testing.T) {
+	cmd := NewCommand("test", "Test command")
+	buf := &bytes.Buffer{}
+	cmd.SetOutput(buf)
+	cmd.Action = func(ctx context.Context, cmd *Command) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	if err := cmd.PrintUsage(); err != nil {
+		t.Fatalf("PrintUsage failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "-timeout") {
+		t.Error("Expected -timeout flag even when no timeout is configured")
+	}
+
+	var timeoutErr *TimeoutError
+	if err := cmd.Run([]string{"--timeout", "10ms"}); !errors.As(err, &timeoutErr) {
+		t.Fatalf("Expected *TimeoutError, got %v", err)
+	}
+	if timeoutErr.Timeout != 10*time.Millisecond {
+		t.Errorf("Expected timeout of 10ms, got %s", timeoutErr.Timeout)
+	}
+}
+
+func TestTimeoutError_WrappedDeadline(t *testing.T) {
+	err := &TimeoutError{Command: "test", Timeout: time.Second, Err: fmt.Errorf("query: %w", context.DeadlineExceeded)}
+	if err.Error() != `command "test" timed out after 1s` {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+}
+
+func TestProgram_Timeout(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.Timeout = 10 * time.Millisecond
+	prog.SetOutput(&bytes.Buffer{})
+
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	prog.Commands = []*Command{testCmd}
+
+	err := prog.Run([]string{"testapp", "test"})
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Errorf("Expected *TimeoutError from program default, got %v", err)
+	}
+}
+
+func TestProgram_TimeoutCommandOverridesDefault(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.Timeout = time.Hour
+	prog.SetOutput(&bytes.Buffer{})
+
+	testCmd := NewCommand("test", "Test command")
+	testCmd.Timeout = 10 * time.Millisecond
+	testCmd.Action = func(ctx context.Context, cmd *Command) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	prog.Commands = []*Command{testCmd}
+
+	err := prog.Run([]string{"testapp", "test"})
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Expected *TimeoutError, got %v", err)
+	}
+	if timeoutErr.Timeout != 10*time.Millisecond {
+		t.Errorf("Expected command timeout to win, got %s", timeoutErr.Timeout)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if code := ExitCode(nil); code != 0 {
+		t.Errorf("Expected 0 for nil error, got %d", code)
+	}
+	if code := ExitCode(errors.New("boom")); code != 1 {
+		t.Errorf("Expected 1 for plain error, got %d", code)
+	}
+	wrapped := errors.Join(errors.New("outer"), &TimeoutError{Command: "x", Timeout: time.Second})
+	if code := ExitCode(wrapped); code != 124 {
+		t.Errorf("Expected 124 for wrapped timeout error, got %d", code)
+	}
+}
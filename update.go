@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// 发布通道
+const (
+	ChannelStable = "stable" // 正式版本
+	ChannelBeta   = "beta"   // 预发布版本（同时包含正式版本）
+)
+
+// updateTryTimeout 更新前试运行新版本的最长时间
+const updateTryTimeout = 10 * time.Second
+
+// channel 返回发布版本所属的通道
+func (r *Release) channel(v semver) string {
+	switch {
+	case r.Channel != "":
+		return r.Channel
+	case v.prerelease():
+		return ChannelBeta
+	default:
+		return ChannelStable
+	}
+}
+
+// asset 返回当前平台的可执行文件
+func (r *Release) asset() *Asset {
+	for i := range r.Assets {
+		if r.Assets[i].OS == runtime.GOOS && r.Assets[i].Arch == runtime.GOARCH {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// latestRelease 返回通道中比 current 新、且包含当前平台文件的最新版本，没有时返回 nil
+//
+// 通道包含自身的版本和正式版本；版本号无效的发布会被忽略。
+func latestRelease(releases []Release, current, channel string) (*Release, error) {
+	cur, err := parseSemver(current)
+	if err != nil {
+		return nil, fmt.Errorf("current version %q is not a semantic version", current)
+	}
+
+	var latest *Release
+	var latestVer semver
+	for i := range releases {
+		r := &releases[i]
+		v, err := parseSemver(r.Version)
+		if err != nil || v.compare(cur) <= 0 || r.asset() == nil {
+			continue
+		}
+		if ch := r.channel(v); ch != channel && ch != ChannelStable {
+			continue
+		}
+		if latest == nil || v.compare(latestVer) > 0 {
+			latest, latestVer = r, v
+		}
+	}
+	return latest, nil
+}
+
+// updateChannel 返回默认的发布通道
+func (p *Program) updateChannel() string {
+	if p.UpdateChannel != "" {
+		return p.UpdateChannel
+	}
+	return ChannelStable
+}
+
+// updateCommand 创建内置的 update 命令
+func (p *Program) updateCommand() *Command {
+	var check bool
+	var channel string
+
+	cmd := NewCommand("update", "Update to the latest version")
+	cmd.Description = "Download the latest release, verify it and replace the running executable"
+	cmd.HideOutputFlag = true
+	cmd.Flags.BoolVar(&check, "check", false, "Only check whether a newer version is available")
+	cmd.Flags.StringVar(&channel, "channel", p.updateChannel(), "Release channel (stable, beta)")
+	cmd.Action = func(ctx context.Context, cmd *Command) error {
+		current := p.BuildInfo().Version
+		releases, err := p.UpdateSource.Releases(ctx)
+		if err != nil {
+			return fmt.Errorf("fetch releases: %w", err)
+		}
+		rel, err := latestRelease(releases, current, channel)
+		if err != nil {
+			return err
+		}
+		if rel == nil {
+			_, err := fmt.Fprintf(cmd.Stdout(), "%s %s is up to date (%s channel)\n", p.Name, current, channel)
+			return err
+		}
+
+		if check {
+			_, err := fmt.Fprintf(cmd.Stdout(), "A new version is available: %s -> %s\nRun '%s update' to install it.\n",
+				current, rel.Version, p.Name)
+			return err
+		}
+
+		_, _ = fmt.Fprintf(cmd.Stderr(), "Downloading %s %s...\n", p.Name, rel.Version)
+		if err := p.applyUpdate(ctx, rel); err != nil {
+			return fmt.Errorf("update to %s: %w", rel.Version, err)
+		}
+		if _, err := fmt.Fprintf(cmd.Stdout(), "Updated %s %s -> %s\n", p.Name, current, rel.Version); err != nil {
+			return err
+		}
+		if rel.Notes != "" {
+			_, err = fmt.Fprintf(cmd.Stdout(), "\n%s\n", rel.Notes)
+		}
+		return err
+	}
+	return cmd
+}
+
+// executablePath 返回当前可执行文件的真实路径
+func (p *Program) executablePath() (string, error) {
+	exe := p.executable
+	if exe == nil {
+		exe = os.Executable
+	}
+	path, err := exe()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// applyUpdate 下载、校验并替换当前可执行文件
+//
+// 新文件先下载到可执行文件所在目录，校验 SHA-256（设置了 UpdatePublicKey 时还会校验签名）
+// 并试运行 version 命令，然后通过重命名原子地替换；替换失败时恢复原文件。
+func (p *Program) applyUpdate(ctx context.Context, rel *Release) error {
+	asset := rel.asset()
+	exe, err := p.executablePath()
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+
+	dir, base := filepath.Split(exe)
+	tmp, err := os.CreateTemp(dir, "."+base+".new-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // 替换成功后文件已不存在
+
+	err = p.downloadAsset(ctx, tmp, asset)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := p.tryExecutable(ctx, tmp.Name()); err != nil {
+		return err
+	}
+	return replaceFile(exe, tmp.Name())
+}
+
+// downloadAsset 将发布文件写入 w，并校验摘要和签名
+func (p *Program) downloadAsset(ctx context.Context, w io.Writer, asset *Asset) error {
+	want, err := hex.DecodeString(asset.SHA256)
+	if err != nil || len(want) != sha256.Size {
+		return errors.New("release has no valid SHA-256 checksum")
+	}
+
+	body, err := p.UpdateSource.Open(ctx, *asset)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, h), body); err != nil {
+		return err
+	}
+	sum := h.Sum(nil)
+	if !bytes.Equal(sum, want) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %x", asset.SHA256, sum)
+	}
+
+	if p.UpdatePublicKey == nil {
+		return nil
+	}
+	sig, err := base64.StdEncoding.DecodeString(asset.Signature)
+	if err != nil || asset.Signature == "" {
+		return errors.New("release is not signed")
+	}
+	if !ed25519.Verify(p.UpdatePublicKey, sum, sig) {
+		return errors.New("invalid release signature")
+	}
+	return nil
+}
+
+// tryExecutable 试运行新版本的 version 命令，确认它可以在当前平台上执行
+func (p *Program) tryExecutable(ctx context.Context, path string) error {
+	if p.HideVersionCommand {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTryTimeout)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, path, "version")
+	c.Env = p.Environ()
+	c.Dir = p.dir
+	out, err := c.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("new version failed to run: %w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// replaceFile 用 src 替换 dst，原文件先移动到备份位置，失败时恢复
+//
+// Windows 上无法覆盖正在运行的可执行文件，但可以将其重命名，因此分三步：
+// dst 重命名为备份，src 重命名为 dst，第二步失败时将备份重命名回 dst。
+func replaceFile(dst, src string) error {
+	dir, base := filepath.Split(dst)
+	backup := filepath.Join(dir, "."+base+".old")
+	_ = os.Remove(backup)
+
+	if err := os.Rename(dst, backup); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		if rerr := os.Rename(backup, dst); rerr != nil {
+			return fmt.Errorf("%w (restore failed: %v, previous version is at %s)", err, rerr, backup)
+		}
+		return err
+	}
+	// Windows 上正在运行的文件无法删除，留待下次更新时清理
+	_ = os.Remove(backup)
+	return nil
+}
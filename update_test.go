@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testRelease 在 dir 中写入发布文件并返回对应的发布版本
+func testRelease(t *testing.T, dir, version, content string) Release {
+	t.Helper()
+	name := "testapp-" + version
+	writeFile(t, filepath.Join(dir, name), content)
+	sum := sha256.Sum256([]byte(content))
+	return Release{
+		Version: version,
+		Assets: []Asset{
+			{OS: "plan9", Arch: "mips", URL: "missing", SHA256: hex.EncodeToString(sum[:])},
+			{OS: runtime.GOOS, Arch: runtime.GOARCH, URL: name, SHA256: hex.EncodeToString(sum[:])},
+		},
+	}
+}
+
+// writeManifest 写入 releases.json
+func writeManifest(t *testing.T, dir string, releases ...Release) {
+	t.Helper()
+	data, err := json.Marshal(releaseManifest{Releases: releases})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "releases.json"), string(data))
+}
+
+// newUpdateProgram 创建从 releaseDir 更新、可执行文件为 exe 的程序
+func newUpdateProgram(t *testing.T, releaseDir string) (*Program, string, *bytes.Buffer) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("test releases are shell scripts")
+	}
+	exe := filepath.Join(t.TempDir(), "testapp")
+	writeFile(t, exe, "#!/bin/sh\necho testapp version 1.0.0\n")
+	if err := os.Chmod(exe, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	prog := NewProgram("testapp", "1.0.0")
+	prog.UpdateSource = &DirSource{Dir: releaseDir}
+	prog.executable = func() (string, error) { return exe, nil }
+	buf := &bytes.Buffer{}
+	prog.SetOutput(buf)
+	return prog, exe, buf
+}
+
+// assertExecutable 检查可执行文件的内容，以及目录中没有残留的临时文件
+func assertExecutable(t *testing.T, exe, want string) {
+	t.Helper()
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), want) {
+		t.Errorf("Expected executable to contain %q, got %q", want, data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(exe))
+	if len(entries) != 1 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("Expected only the executable to remain, got %v", names)
+	}
+}
+
+func TestLatestRelease(t *testing.T) {
+	asset := []Asset{{OS: runtime.GOOS, Arch: runtime.GOARCH}}
+	releases := []Release{
+		{Version: "1.1.0", Assets: asset},
+		{Version: "1.3.0", Assets: []Asset{{OS: "plan9", Arch: "mips"}}},
+		{Version: "1.2.0", Assets: asset},
+		{Version: "1.4.0-beta.1", Assets: asset},
+		{Version: "1.5.0", Channel: "nightly", Assets: asset},
+		{Version: "bogus", Assets: asset},
+		{Version: "0.9.0", Assets: asset},
+	}
+
+	tests := []struct {
+		current, channel, want string
+	}{
+		{"1.0.0", ChannelStable, "1.2.0"},
+		{"1.0.0", ChannelBeta, "1.4.0-beta.1"},
+		{"1.0.0", "nightly", "1.5.0"},
+		{"1.2.0", ChannelStable, ""},
+		{"v1.4.0-alpha", ChannelStable, ""},
+		{"1.4.0-alpha", ChannelBeta, "1.4.0-beta.1"},
+	}
+	for _, tt := range tests {
+		rel, err := latestRelease(releases, tt.current, tt.channel)
+		if err != nil {
+			t.Fatalf("%s/%s: %v", tt.current, tt.channel, err)
+		}
+		got := ""
+		if rel != nil {
+			got = rel.Version
+		}
+		if got != tt.want {
+			t.Errorf("%s/%s: expected %q, got %q", tt.current, tt.channel, tt.want, got)
+		}
+	}
+
+	if _, err := latestRelease(releases, "dev", ChannelStable); err == nil || !strings.Contains(err.Error(), "not a semantic version") {
+		t.Errorf("Expected invalid current version error, got %v", err)
+	}
+}
+
+func TestUpdateCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir,
+		testRelease(t, dir, "1.1.0", "#!/bin/sh\necho testapp version 1.1.0\n"),
+		testRelease(t, dir, "2.0.0-beta.1", "#!/bin/sh\necho testapp version 2.0.0-beta.1\n"),
+	)
+	prog, exe, buf := newUpdateProgram(t, dir)
+
+	if err := prog.Run([]string{"testapp", "update", "-check"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "A new version is available: 1.0.0 -> 1.1.0") {
+		t.Errorf("Expected update notice, got %q", buf.String())
+	}
+	assertExecutable(t, exe, "version 1.0.0")
+
+	buf.Reset()
+	if err := prog.Run([]string{"testapp", "update", "-channel", "beta"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "Updated testapp 1.0.0 -> 2.0.0-beta.1") {
+		t.Errorf("Expected update message, got %q", buf.String())
+	}
+	assertExecutable(t, exe, "version 2.0.0-beta.1")
+	if info, err := os.Stat(exe); err != nil || info.Mode().Perm() != 0o755 {
+		t.Errorf("Expected executable mode to be preserved, got %v (%v)", info.Mode(), err)
+	}
+
+	prog.Version = "2.0.0-beta.1"
+	buf.Reset()
+	if err := prog.Run([]string{"testapp", "update", "-channel", "beta"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "testapp 2.0.0-beta.1 is up to date (beta channel)") {
+		t.Errorf("Expected up to date message, got %q", buf.String())
+	}
+}
+
+func TestUpdateCommand_Disabled(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.SetOutput(&bytes.Buffer{})
+	if prog.Get("update") != nil {
+		t.Error("Expected update command to require UpdateSource")
+	}
+}
+
+func TestUpdateCommand_VerificationFailures(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(rel Release) Release {
+		sum, _ := hex.DecodeString(rel.Assets[1].SHA256)
+		rel.Assets[1].Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, sum))
+		return rel
+	}
+	script := "#!/bin/sh\necho testapp version 1.1.0\n"
+
+	tests := []struct {
+		name    string
+		release func(dir string) Release
+		key     ed25519.PublicKey
+		wantErr string
+	}{
+		{
+			name: "checksum mismatch",
+			release: func(dir string) Release {
+				rel := testRelease(t, dir, "1.1.0", script)
+				writeFile(t, filepath.Join(dir, "testapp-1.1.0"), script+"# tampered\n")
+				return rel
+			},
+			wantErr: "checksum mismatch",
+		},
+		{
+			name: "missing checksum",
+			release: func(dir string) Release {
+				rel := testRelease(t, dir, "1.1.0", script)
+				rel.Assets[1].SHA256 = ""
+				return rel
+			},
+			wantErr: "no valid SHA-256 checksum",
+		},
+		{
+			name:    "unsigned",
+			release: func(dir string) Release { return testRelease(t, dir, "1.1.0", script) },
+			key:     pub,
+			wantErr: "release is not signed",
+		},
+		{
+			name: "wrong key",
+			release: func(dir string) Release {
+				return sign(testRelease(t, dir, "1.1.0", script))
+			},
+			key:     make(ed25519.PublicKey, ed25519.PublicKeySize),
+			wantErr: "invalid release signature",
+		},
+		{
+			name:    "broken binary",
+			release: func(dir string) Release { return testRelease(t, dir, "1.1.0", "#!/bin/sh\necho boom\nexit 1\n") },
+			wantErr: "new version failed to run: exit status 1: boom",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeManifest(t, dir, tt.release(dir))
+			prog, exe, _ := newUpdateProgram(t, dir)
+			prog.UpdatePublicKey = tt.key
+
+			err := prog.Run([]string{"testapp", "update"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			assertExecutable(t, exe, "version 1.0.0")
+		})
+	}
+
+	t.Run("signed", func(t *testing.T) {
+		dir := t.TempDir()
+		writeManifest(t, dir, sign(testRelease(t, dir, "1.1.0", script)))
+		prog, exe, _ := newUpdateProgram(t, dir)
+		prog.UpdatePublicKey = pub
+
+		if err := prog.Run([]string{"testapp", "update"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		assertExecutable(t, exe, "version 1.1.0")
+	})
+}
+
+func TestReplaceFile(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "app")
+	src := filepath.Join(dir, ".app.new")
+	writeFile(t, dst, "old")
+	writeFile(t, src, "new")
+
+	if err := replaceFile(dst, src); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := os.ReadFile(dst)
+	if err != nil || string(data) != "new" {
+		t.Errorf("Expected file to be replaced, got %q (%v)", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if !slices.EqualFunc(entries, []string{"app"}, func(e os.DirEntry, name string) bool { return e.Name() == name }) {
+		t.Errorf("Expected no temporary or backup file to remain, got %v", entries)
+	}
+}
+
+func TestReplaceFile_Rollback(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "app")
+	writeFile(t, dst, "old")
+
+	// 源文件不存在：dst 已移动到备份位置后第二次重命名失败，需要将备份移回
+	if err := replaceFile(dst, filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("Expected error for missing source")
+	}
+	data, err := os.ReadFile(dst)
+	if err != nil || string(data) != "old" {
+		t.Errorf("Expected the old binary to be restored, got %q (%v)", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if !slices.EqualFunc(entries, []string{"app"}, func(e os.DirEntry, name string) bool { return e.Name() == name }) {
+		t.Errorf("Expected no backup file to remain, got %v", entries)
+	}
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultUpdateCheckInterval 两次检查新版本之间的默认间隔
+const DefaultUpdateCheckInterval = 24 * time.Hour
+
+// updateNoticeTimeout 后台检查新版本的最长时间
+const updateNoticeTimeout = 5 * time.Second
+
+// UpdateChecker 检查最新的版本号
+type UpdateChecker interface {
+	LatestVersion(ctx context.Context) (string, error)
+}
+
+// UpdateCheckerFunc 将函数适配为 UpdateChecker
+type UpdateCheckerFunc func(ctx context.Context) (string, error)
+
+// LatestVersion 实现 UpdateChecker 接口
+func (f UpdateCheckerFunc) LatestVersion(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// ReleaseChecker 返回从发布源读取通道中最新版本的 UpdateChecker
+func ReleaseChecker(src ReleaseSource, channel string) UpdateChecker {
+	return UpdateCheckerFunc(func(ctx context.Context) (string, error) {
+		releases, err := src.Releases(ctx)
+		if err != nil {
+			return "", err
+		}
+		// 0.0.0-0 是最低的语义化版本，因此任何有效的发布版本都比它新
+		rel, err := latestRelease(releases, "0.0.0-0", channel)
+		if err != nil || rel == nil {
+			return "", err
+		}
+		return rel.Version, nil
+	})
+}
+
+// updateCheckCache 缓存的检查结果
+type updateCheckCache struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest"`
+}
+
+// startUpdateCheck 按需在后台检查新版本，返回在命令结束后调用的提示函数
+//
+// 距上次检查未超过 UpdateCheckInterval 时使用缓存的结果，否则在后台检查并写入缓存。
+// 检查不受 ctx 取消的影响，最长持续 updateNoticeTimeout；检查失败（离线、出错或超时）时
+// 同样记录检查时间，避免每次执行都重新检查。提示函数不等待检查完成，
+// 结果尚未就绪时留待之后的执行从缓存中提示。执行 update 命令时不检查。
+func (p *Program) startUpdateCheck(ctx context.Context, args []string) func() {
+	if !p.updateNoticeEnabled() || (p.UpdateSource != nil && len(args) > 1 && args[1] == "update") {
+		return func() {}
+	}
+
+	path := p.updateCheckCacheFile()
+	cache, _ := readUpdateCheckCache(path)
+	if cache != nil && p.Now().Sub(cache.CheckedAt) < p.updateCheckInterval() {
+		return func() { p.printUpdateNotice(cache.Latest) }
+	}
+
+	// 后台 goroutine 可能在 RunContext 返回后继续执行，因此不再访问 p
+	checker, now := p.UpdateChecker, p.Now()
+	result := make(chan string, 1)
+	go func() {
+		defer close(result)
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), updateNoticeTimeout)
+		defer cancel()
+		latest, err := checker.LatestVersion(ctx)
+		if err != nil {
+			// 保留上次已知的最新版本，只更新检查时间
+			latest = ""
+			if cache != nil {
+				latest = cache.Latest
+			}
+		}
+		_ = writeUpdateCheckCache(path, &updateCheckCache{CheckedAt: now, Latest: latest})
+		if err == nil {
+			result <- latest
+		}
+	}()
+	return func() {
+		select {
+		case latest, ok := <-result:
+			if ok {
+				p.printUpdateNotice(latest)
+			}
+		default:
+		}
+	}
+}
+
+// updateNoticeEnabled 判断是否检查新版本
+//
+// 设置了 <PREFIX>_NO_UPDATE_NOTIFIER 或 CI 环境变量、标准错误不是终端、
+// 或当前版本不是语义化版本时不检查。
+func (p *Program) updateNoticeEnabled() bool {
+	if p.UpdateChecker == nil || p.Getenv(p.EnvPrefix()+"_NO_UPDATE_NOTIFIER") != "" || p.Getenv("CI") != "" {
+		return false
+	}
+	if !isTerminal(p.Stderr()) || p.updateCheckCacheFile() == "" {
+		return false
+	}
+	_, err := parseSemver(p.BuildInfo().Version)
+	return err == nil
+}
+
+// updateCheckInterval 返回两次检查之间的间隔
+func (p *Program) updateCheckInterval() time.Duration {
+	if p.UpdateCheckInterval > 0 {
+		return p.UpdateCheckInterval
+	}
+	return DefaultUpdateCheckInterval
+}
+
+// updateCheckCacheFile 返回缓存文件路径，无法确定用户缓存目录时返回空字符串
+func (p *Program) updateCheckCacheFile() string {
+	dir := p.userCacheDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, p.Name, "update_check.json")
+}
+
+// printUpdateNotice 最新版本比当前版本新时，在标准错误输出一行提示
+func (p *Program) printUpdateNotice(latest string) {
+	current := p.BuildInfo().Version
+	cur, err := parseSemver(current)
+	if err != nil {
+		return
+	}
+	v, err := parseSemver(latest)
+	if err != nil || v.compare(cur) <= 0 {
+		return
+	}
+
+	msg := fmt.Sprintf("A new version of %s is available: %s -> %s", p.Name, current, latest)
+	if p.UpdateSource != nil {
+		msg += fmt.Sprintf(" (run '%s update' to install it)", p.Name)
+	}
+	p.printWarning("%s", msg)
+}
+
+// readUpdateCheckCache 读取缓存的检查结果
+func readUpdateCheckCache(path string) (*updateCheckCache, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cache updateCheckCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
+
+// writeUpdateCheckCache 写入检查结果（先写临时文件再重命名，避免并发执行时读到不完整的内容）
+func writeUpdateCheckCache(path string, cache *updateCheckCache) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".update_check-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
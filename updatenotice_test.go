@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ttyWriter 模拟终端的输出
+type ttyWriter struct {
+	io.Writer
+}
+
+func (ttyWriter) IsTerminal() bool { return true }
+
+// newNoticeProgram 创建带有更新检查的程序，返回标准错误和检查次数
+func newNoticeProgram(t *testing.T, latest string) (*Program, *bytes.Buffer, *atomic.Int32) {
+	t.Helper()
+	calls := &atomic.Int32{}
+	prog := NewProgram("testapp", "1.0.0")
+	prog.UpdateChecker = UpdateCheckerFunc(func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return latest, nil
+	})
+	prog.Commands = []*Command{NewCommand("noop", "Do nothing")}
+	prog.SetEnviron([]string{"XDG_CACHE_HOME=" + t.TempDir(), "HOME=" + t.TempDir(), "LocalAppData=" + t.TempDir()})
+	prog.SetClock(func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) })
+
+	stderr := &bytes.Buffer{}
+	prog.SetStdout(&bytes.Buffer{})
+	prog.SetStderr(ttyWriter{stderr})
+	return prog, stderr, calls
+}
+
+// waitForCache 等待后台检查写入缓存
+func waitForCache(t *testing.T, prog *Program) *updateCheckCache {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cache, err := readUpdateCheckCache(prog.updateCheckCacheFile()); err == nil {
+			return cache
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for the update check cache")
+	return nil
+}
+
+func TestUpdateNotice(t *testing.T) {
+	prog, stderr, calls := newNoticeProgram(t, "1.2.0")
+
+	if err := prog.Run([]string{"testapp", "noop"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	cache := waitForCache(t, prog)
+	if cache.Latest != "1.2.0" || !cache.CheckedAt.Equal(prog.Now()) {
+		t.Errorf("Unexpected cache: %+v", cache)
+	}
+
+	// 缓存有效期内不再检查，使用缓存的结果提示
+	stderr.Reset()
+	if err := prog.Run([]string{"testapp", "noop"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := "A new version of testapp is available: 1.0.0 -> 1.2.0\n"
+	if stderr.String() != want {
+		t.Errorf("Expected notice %q, got %q", want, stderr.String())
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 check within the interval, got %d", n)
+	}
+
+	// 缓存过期后重新检查
+	prog.SetClock(func() time.Time { return time.Date(2024, 1, 2, 0, 0, 1, 0, time.UTC) })
+	if err := prog.Run([]string{"testapp", "noop"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cache := waitForCache(t, prog); cache.CheckedAt.Equal(prog.Now()) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("Expected a new check after the interval, got %d checks", n)
+	}
+}
+
+func TestUpdateNotice_DoesNotWaitForCheck(t *testing.T) {
+	prog, stderr, _ := newNoticeProgram(t, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+	prog.UpdateChecker = UpdateCheckerFunc(func(ctx context.Context) (string, error) {
+		<-release
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		return "1.2.0", nil
+	})
+	// 命令结束并取消了调用方的 context，检查仍未完成
+	prog.Commands[0].Action = func(context.Context, *Command) error {
+		cancel()
+		return nil
+	}
+
+	start := time.Now()
+	if err := prog.RunContext(ctx, []string{"testapp", "noop"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected RunContext not to wait for the check, took %s", elapsed)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Expected no notice before the check completes, got %q", stderr.String())
+	}
+
+	// 检查在命令结束后完成，结果在下次执行时从缓存中提示
+	close(release)
+	if cache := waitForCache(t, prog); cache.Latest != "1.2.0" {
+		t.Errorf("Expected the check to finish after the command, got %+v", cache)
+	}
+	if err := prog.Run([]string{"testapp", "noop"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), "1.0.0 -> 1.2.0") {
+		t.Errorf("Expected notice on the next run, got %q", stderr.String())
+	}
+}
+
+func TestUpdateNotice_UpToDate(t *testing.T) {
+	prog, stderr, _ := newNoticeProgram(t, "1.0.0")
+	prog.UpdateSource = &DirSource{Dir: t.TempDir()}
+	if err := writeUpdateCheckCache(prog.updateCheckCacheFile(), &updateCheckCache{CheckedAt: prog.Now(), Latest: "1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := prog.Run([]string{"testapp", "noop"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Expected no notice when up to date, got %q", stderr.String())
+	}
+
+	prog.printUpdateNotice("2.0.0")
+	if !strings.Contains(stderr.String(), "(run 'testapp update' to install it)") {
+		t.Errorf("Expected notice to mention the update command, got %q", stderr.String())
+	}
+}
+
+func TestUpdateNotice_Disabled(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(prog *Program)
+	}{
+		{"CI", func(prog *Program) { prog.SetEnviron(append(prog.Environ(), "CI=true")) }},
+		{"env", func(prog *Program) { prog.SetEnviron(append(prog.Environ(), "TESTAPP_NO_UPDATE_NOTIFIER=1")) }},
+		{"non-TTY", func(prog *Program) { prog.SetStderr(&bytes.Buffer{}) }},
+		{"dev version", func(prog *Program) { prog.Version = "dev" }},
+		{"update command", func(prog *Program) {
+			prog.UpdateSource = &DirSource{Dir: t.TempDir()}
+			prog.Commands[0].Name = "update"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, _, calls := newNoticeProgram(t, "1.2.0")
+			tt.setup(prog)
+			if err := prog.Run([]string{"testapp", prog.Commands[0].Name}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			time.Sleep(20 * time.Millisecond)
+			if n := calls.Load(); n != 0 {
+				t.Errorf("Expected no update check, got %d", n)
+			}
+			if _, err := os.Stat(filepath.Dir(prog.updateCheckCacheFile())); err == nil {
+				t.Error("Expected no cache to be written")
+			}
+		})
+	}
+}
+
+func TestUpdateNotice_CheckFailure(t *testing.T) {
+	prog, stderr, calls := newNoticeProgram(t, "")
+	path := prog.updateCheckCacheFile()
+	stale := prog.Now().Add(-48 * time.Hour)
+	if err := writeUpdateCheckCache(path, &updateCheckCache{CheckedAt: stale, Latest: "1.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+	prog.UpdateChecker = UpdateCheckerFunc(func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return "", errors.New("offline")
+	})
+
+	if err := prog.Run([]string{"testapp", "noop"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Expected check failures to be silent, got %q", stderr.String())
+	}
+
+	// 失败同样记录检查时间，并保留上次已知的最新版本
+	var cache *updateCheckCache
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cache = waitForCache(t, prog); cache.CheckedAt.Equal(prog.Now()) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !cache.CheckedAt.Equal(prog.Now()) || cache.Latest != "1.1.0" {
+		t.Fatalf("Expected failed check to be recorded, got %+v", cache)
+	}
+
+	// 间隔内不再重试
+	if err := prog.Run([]string{"testapp", "noop"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected no retry within the interval, got %d checks", n)
+	}
+}
+
+func TestReleaseChecker(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir,
+		testRelease(t, dir, "1.1.0", "a"),
+		testRelease(t, dir, "1.2.0-beta.1", "b"),
+	)
+	for channel, want := range map[string]string{ChannelStable: "1.1.0", ChannelBeta: "1.2.0-beta.1"} {
+		got, err := ReleaseChecker(&DirSource{Dir: dir}, channel).LatestVersion(t.Context())
+		if err != nil || got != want {
+			t.Errorf("%s: expected %q, got %q (%v)", channel, want, got, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// ReleaseSource 提供可供更新的发布版本
+type ReleaseSource interface {
+	// Releases 返回所有发布版本（顺序不限）
+	Releases(ctx context.Context) ([]Release, error)
+	// Open 打开发布文件的内容
+	Open(ctx context.Context, asset Asset) (io.ReadCloser, error)
+}
+
+// Release 一个发布版本
+type Release struct {
+	Version string  `json:"version"`           // 语义化版本
+	Channel string  `json:"channel,omitempty"` // 发布通道（默认预发布版本为 beta，其它为 stable）
+	Notes   string  `json:"notes,omitempty"`   // 更新说明
+	Assets  []Asset `json:"assets"`            // 各平台的可执行文件
+}
+
+// Asset 发布版本中某个平台的可执行文件
+type Asset struct {
+	OS        string `json:"os"`                  // 目标操作系统（runtime.GOOS）
+	Arch      string `json:"arch"`                // 目标架构（runtime.GOARCH）
+	URL       string `json:"url"`                 // 文件位置（相对路径相对于清单）
+	SHA256    string `json:"sha256"`              // 文件的 SHA-256（十六进制）
+	Signature string `json:"signature,omitempty"` // 对 SHA-256 摘要的 ed25519 签名（base64）
+}
+
+// releaseManifest 发布清单（releases.json）的格式
+type releaseManifest struct {
+	Releases []Release `json:"releases"`
+}
+
+// HTTPSource 从 HTTP(S) 地址读取 JSON 发布清单
+//
+// 清单格式为 {"releases": [...]}，发布文件的相对 URL 相对于清单地址解析。
+type HTTPSource struct {
+	URL    string       // 清单地址
+	Client *http.Client // HTTP 客户端（默认 http.DefaultClient）
+}
+
+// Releases 实现 ReleaseSource 接口
+func (s *HTTPSource) Releases(ctx context.Context) ([]Release, error) {
+	body, err := s.get(ctx, s.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return decodeManifest(body, s.URL)
+}
+
+// Open 实现 ReleaseSource 接口
+func (s *HTTPSource) Open(ctx context.Context, asset Asset) (io.ReadCloser, error) {
+	base, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := url.Parse(asset.URL)
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, base.ResolveReference(ref).String())
+}
+
+// get 发送 GET 请求，非 2xx 状态码视为错误
+func (s *HTTPSource) get(ctx context.Context, u string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// DirSource 从本地目录读取发布版本
+//
+// 目录中的 releases.json 为发布清单（格式与 HTTPSource 相同），发布文件的 URL 为相对于目录的路径。
+type DirSource struct {
+	Dir string
+}
+
+// Releases 实现 ReleaseSource 接口
+func (s *DirSource) Releases(ctx context.Context) ([]Release, error) {
+	path := filepath.Join(s.Dir, "releases.json")
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return decodeManifest(f, path)
+}
+
+// Open 实现 ReleaseSource 接口
+func (s *DirSource) Open(ctx context.Context, asset Asset) (io.ReadCloser, error) {
+	if !filepath.IsLocal(filepath.FromSlash(asset.URL)) {
+		return nil, fmt.Errorf("asset path %q is outside the release directory", asset.URL)
+	}
+	return os.Open(filepath.Join(s.Dir, filepath.FromSlash(asset.URL)))
+}
+
+// decodeManifest 解析发布清单，name 用于错误信息
+func decodeManifest(r io.Reader, name string) ([]Release, error) {
+	var m releaseManifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("%s: invalid release manifest: %w", name, err)
+	}
+	return m.Releases, nil
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTTPSource(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/app/releases.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"releases": [{"version": "1.1.0", "assets": [{"os": "linux", "arch": "amd64", "url": "files/app-1.1.0"}]}]}`)
+	})
+	mux.HandleFunc("/app/files/app-1.1.0", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "binary")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	src := &HTTPSource{URL: srv.URL + "/app/releases.json", Client: srv.Client()}
+	releases, err := src.Releases(t.Context())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(releases) != 1 || releases[0].Version != "1.1.0" || len(releases[0].Assets) != 1 {
+		t.Fatalf("Unexpected releases: %+v", releases)
+	}
+
+	body, err := src.Open(t.Context(), releases[0].Assets[0])
+	if err != nil {
+		t.Fatalf("Expected relative asset URL to resolve, got %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	_ = body.Close()
+	if string(data) != "binary" {
+		t.Errorf("Expected asset content, got %q", data)
+	}
+
+	if _, err := src.Open(t.Context(), Asset{URL: "missing"}); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected 404 error, got %v", err)
+	}
+	bad := &HTTPSource{URL: srv.URL + "/app/files/app-1.1.0", Client: srv.Client()}
+	if _, err := bad.Releases(t.Context()); err == nil || !strings.Contains(err.Error(), "invalid release manifest") {
+		t.Errorf("Expected invalid manifest error, got %v", err)
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "releases.json"), `{"releases": [{"version": "1.1.0", "assets": [{"os": "linux", "arch": "amd64", "url": "bin/app"}]}]}`)
+	writeFile(t, filepath.Join(dir, "bin", "app"), "binary")
+
+	src := &DirSource{Dir: dir}
+	releases, err := src.Releases(t.Context())
+	if err != nil || len(releases) != 1 {
+		t.Fatalf("Unexpected releases: %+v (%v)", releases, err)
+	}
+	body, err := src.Open(t.Context(), releases[0].Assets[0])
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	_ = body.Close()
+	if string(data) != "binary" {
+		t.Errorf("Expected asset content, got %q", data)
+	}
+
+	if _, err := src.Open(t.Context(), Asset{URL: "../outside"}); err == nil || !strings.Contains(err.Error(), "outside the release directory") {
+		t.Errorf("Expected path escape to be rejected, got %v", err)
+	}
+	if _, err := (&DirSource{Dir: t.TempDir()}).Releases(t.Context()); err == nil {
+		t.Error("Expected error for missing manifest")
+	}
+}
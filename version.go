@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+// readBuildInfo 读取构建信息（测试时可替换，默认 debug.ReadBuildInfo）
+var readBuildInfo = debug.ReadBuildInfo
+
+// BuildInfo 程序的构建信息
+type BuildInfo struct {
+	Name      string    `json:"name"`               // 程序名称
+	Version   string    `json:"version"`            // 版本
+	Revision  string    `json:"revision,omitempty"` // VCS 修订版本（提交哈希）
+	Time      time.Time `json:"time,omitzero"`      // 提交时间
+	Modified  bool      `json:"modified,omitempty"` // 构建时工作区有未提交的修改
+	GoVersion string    `json:"go_version"`         // 编译使用的 Go 版本
+	OS        string    `json:"os"`                 // 目标操作系统
+	Arch      string    `json:"arch"`               // 目标架构
+}
+
+// ShortRevision 返回修订版本的前 12 个字符
+func (b BuildInfo) ShortRevision() string {
+	if len(b.Revision) > 12 {
+		return b.Revision[:12]
+	}
+	return b.Revision
+}
+
+// BuildInfo 返回程序的构建信息
+//
+// 启用 UseBuildInfo 时从 runtime/debug.ReadBuildInfo 读取 VCS 修订版本、提交时间和修改标记，
+// Version 为空时使用主模块的版本（go install module@version 安装时为模块版本，本地构建时为 "(devel)"）。
+func (p *Program) BuildInfo() BuildInfo {
+	info := BuildInfo{
+		Name:      p.Name,
+		Version:   p.Version,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if !p.UseBuildInfo {
+		return info
+	}
+
+	bi, ok := readBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "" {
+		info.Version = bi.Main.Version
+	}
+	if bi.GoVersion != "" {
+		info.GoVersion = bi.GoVersion
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.Time, _ = time.Parse(time.RFC3339, s.Value)
+		case "vcs.modified":
+			info.Modified, _ = strconv.ParseBool(s.Value)
+		}
+	}
+	return info
+}
+
+// versionCommand 创建内置的 version 命令
+func (p *Program) versionCommand() *Command {
+	cmd := DefaultVersionCommand()
+	cmd.Flags.Bool("json", false, "Print build information as JSON")
+	cmd.Flags.Bool("verbose", false, "Print detailed build information")
+	cmd.Action = func(ctx context.Context, cmd *Command) error {
+		switch {
+		case cmd.Flags.Lookup("json").Value.String() == "true":
+			return p.writeVersionJSON(cmd.Stdout())
+		case cmd.Flags.Lookup("verbose").Value.String() == "true":
+			return p.writeVersionVerbose(cmd.Stdout())
+		default:
+			return p.writeVersion(cmd.Stdout())
+		}
+	}
+	return cmd
+}
+
+// writeVersion 写出一行版本信息（version 命令和 -v/--version 标志）
+//
+// 设置了 VersionTemplate 时使用模板渲染 BuildInfo。
+func (p *Program) writeVersion(w io.Writer) error {
+	info := p.BuildInfo()
+	if p.VersionTemplate != "" {
+		tmpl, err := template.New("version").Parse(p.VersionTemplate)
+		if err != nil {
+			return fmt.Errorf("version template: %w", err)
+		}
+		return tmpl.Execute(w, info)
+	}
+
+	line := fmt.Sprintf("%s version %s", info.Name, info.Version)
+	if info.Revision != "" {
+		line += " (" + info.ShortRevision()
+		if info.Modified {
+			line += ", modified"
+		}
+		line += ")"
+	}
+	_, err := fmt.Fprintln(w, line)
+	return err
+}
+
+// writeVersionVerbose 写出完整的构建信息
+func (p *Program) writeVersionVerbose(w io.Writer) error {
+	info := p.BuildInfo()
+
+	var b []byte
+	b = fmt.Appendf(b, "%s version %s\n", info.Name, info.Version)
+	if info.Revision != "" {
+		revision := info.Revision
+		if info.Modified {
+			revision += " (modified)"
+		}
+		b = fmt.Appendf(b, "  Revision:   %s\n", revision)
+	}
+	if !info.Time.IsZero() {
+		b = fmt.Appendf(b, "  Committed:  %s\n", info.Time.Format(time.RFC3339))
+	}
+	b = fmt.Appendf(b, "  Go version: %s\n", info.GoVersion)
+	b = fmt.Appendf(b, "  Platform:   %s/%s\n", info.OS, info.Arch)
+	_, err := w.Write(b)
+	return err
+}
+
+// writeVersionJSON 以 JSON 格式写出构建信息
+func (p *Program) writeVersionJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p.BuildInfo())
+}
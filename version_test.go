@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBuildInfo 替换 readBuildInfo，测试结束后恢复
+func fakeBuildInfo(t *testing.T, version string, settings map[string]string) {
+	old := readBuildInfo
+	t.Cleanup(func() { readBuildInfo = old })
+
+	bi := &debug.BuildInfo{GoVersion: "go1.99.0", Main: debug.Module{Path: "example.com/app", Version: version}}
+	for k, v := range settings {
+		bi.Settings = append(bi.Settings, debug.BuildSetting{Key: k, Value: v})
+	}
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return bi, true }
+}
+
+func runVersion(t *testing.T, prog *Program, args ...string) string {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	prog.SetOutput(buf)
+	if err := prog.Run(append([]string{prog.Name}, args...)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return buf.String()
+}
+
+func TestProgram_BuildInfo(t *testing.T) {
+	fakeBuildInfo(t, "v1.2.3", map[string]string{
+		"vcs.revision": "0123456789abcdef0123",
+		"vcs.time":     "2024-05-06T07:08:09Z",
+		"vcs.modified": "true",
+	})
+
+	prog := NewProgram("testapp", "")
+	info := prog.BuildInfo()
+	if info.Version != "" || info.Revision != "" || info.GoVersion != runtime.Version() {
+		t.Errorf("Expected build info to be ignored unless UseBuildInfo is set, got %+v", info)
+	}
+
+	prog.UseBuildInfo = true
+	info = prog.BuildInfo()
+	want := BuildInfo{
+		Name:      "testapp",
+		Version:   "v1.2.3",
+		Revision:  "0123456789abcdef0123",
+		Time:      time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		Modified:  true,
+		GoVersion: "go1.99.0",
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if info != want {
+		t.Errorf("Expected %+v, got %+v", want, info)
+	}
+
+	prog.Version = "2.0.0"
+	if v := prog.BuildInfo().Version; v != "2.0.0" {
+		t.Errorf("Expected explicit Version to win, got %q", v)
+	}
+}
+
+func TestProgram_VersionOutput(t *testing.T) {
+	fakeBuildInfo(t, "(devel)", map[string]string{
+		"vcs.revision": "0123456789abcdef0123",
+		"vcs.time":     "2024-05-06T07:08:09Z",
+		"vcs.modified": "true",
+	})
+	prog := NewProgram("testapp", "1.0.0")
+	prog.UseBuildInfo = true
+	prog.Commands = []*Command{NewCommand("noop", "Do nothing")}
+
+	if got := runVersion(t, prog, "version"); got != "testapp version 1.0.0 (0123456789ab, modified)\n" {
+		t.Errorf("Unexpected version output: %q", got)
+	}
+	if got := runVersion(t, prog, "noop", "-v"); got != "testapp version 1.0.0 (0123456789ab, modified)\n" {
+		t.Errorf("Unexpected -v output: %q", got)
+	}
+
+	got := runVersion(t, prog, "version", "--verbose")
+	for _, want := range []string{
+		"testapp version 1.0.0\n",
+		"Revision:   0123456789abcdef0123 (modified)\n",
+		"Committed:  2024-05-06T07:08:09Z\n",
+		"Go version: go1.99.0\n",
+		"Platform:   " + runtime.GOOS + "/" + runtime.GOARCH + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected verbose output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	var info BuildInfo
+	if err := json.Unmarshal([]byte(runVersion(t, prog, "version", "--json")), &info); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if info.Revision != "0123456789abcdef0123" || !info.Modified || info.Version != "1.0.0" {
+		t.Errorf("Unexpected JSON build info: %+v", info)
+	}
+}
+
+func TestProgram_UsageUsesBuildInfoVersion(t *testing.T) {
+	fakeBuildInfo(t, "v1.4.2", nil)
+	prog := NewProgram("testapp", "")
+	prog.UseBuildInfo = true
+
+	if got := runVersion(t, prog, "--help"); !strings.HasPrefix(got, "testapp version v1.4.2\n") {
+		t.Errorf("Expected help header to use the build info version, got:\n%s", got)
+	}
+	if got := runVersion(t, prog, "version"); got != "testapp version v1.4.2\n" {
+		t.Errorf("Unexpected version output: %q", got)
+	}
+}
+
+func TestProgram_VersionJSONOmitsEmpty(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	got := runVersion(t, prog, "version", "-json")
+	if strings.Contains(got, "revision") || strings.Contains(got, "time") {
+		t.Errorf("Expected empty VCS fields to be omitted, got:\n%s", got)
+	}
+	if got := runVersion(t, prog, "version"); got != "testapp version 1.0.0\n" {
+		t.Errorf("Expected plain version without build info, got %q", got)
+	}
+}
+
+func TestProgram_VersionTemplate(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.VersionTemplate = "{{.Name}} {{.Version}} {{.OS}}\n"
+	prog.Commands = []*Command{NewCommand("noop", "Do nothing")}
+
+	want := "testapp 1.0.0 " + runtime.GOOS + "\n"
+	if got := runVersion(t, prog, "version"); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := runVersion(t, prog, "noop", "--version"); got != want {
+		t.Errorf("Expected %q from --version, got %q", want, got)
+	}
+
+	prog.VersionTemplate = "{{.Nope"
+	prog.SetOutput(&bytes.Buffer{})
+	if err := prog.Run([]string{"testapp", "version"}); err == nil || !strings.Contains(err.Error(), "version template") {
+		t.Errorf("Expected template error, got %v", err)
+	}
+}
+
+func TestProgram_CustomVersionCommand(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.VersionCommand = NewCommand("version", "Custom version")
+	if got := runVersion(t, prog, "version"); got != "testapp version 1.0.0\n" {
+		t.Errorf("Expected built-in output for custom command without Action, got %q", got)
+	}
+}
+
+func TestProgram_CustomVersionCommandFlags(t *testing.T) {
+	prog := NewProgram("testapp", "1.0.0")
+	prog.VersionCommand = DefaultVersionCommand()
+	prog.VersionCommand.Usage = "Custom version"
+
+	help := runVersion(t, prog, "help", "version")
+	for _, name := range []string{"-json", "-verbose"} {
+		if strings.Contains(help, name) {
+			t.Errorf("Expected custom version command not to list %s, got:\n%s", name, help)
+		}
+	}
+
+	// 内置的 version 命令仍然提供这些标志
+	prog.VersionCommand = nil
+	help = runVersion(t, prog, "help", "version")
+	for _, name := range []string{"-json", "-verbose"} {
+		if !strings.Contains(help, name) {
+			t.Errorf("Expected built-in version command to list %s, got:\n%s", name, help)
+		}
+	}
+}